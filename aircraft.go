@@ -0,0 +1,37 @@
+package main
+
+//-air-thrust, -lift, -drag, -stall-speed and -stall-angle tune the aircraft
+//mode, the sixth M mode, e.g.
+//	go run . -air-thrust 12 -stall-speed 4
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var (
+	airThrust  = flag.Float64("air-thrust", 8, "aircraft mode acceleration at full throttle, units per second squared")
+	airLift    = flag.Float64("lift", 1, "aircraft mode lift per squared airspeed per radian of angle of attack")
+	airDrag    = flag.Float64("drag", 0.035, "aircraft mode drag per squared airspeed")
+	stallSpeed = flag.Float64("stall-speed", 6, "aircraft mode airspeed below which the wing stalls, units per second")
+	stallAngle = flag.Float64("stall-angle", 0.3, "aircraft mode angle of attack past which the wing stalls, radians")
+)
+
+//set up aircraft mode's airframe as asked on the command line
+func setupAirframe(d *sim.Demo) error {
+
+	if *airThrust < 0 || *airLift < 0 || *airDrag < 0 || *stallSpeed < 0 || *stallAngle <= 0 {
+		return fmt.Errorf("-air-thrust, -lift, -drag and -stall-speed must not be below 0, -stall-angle must be above")
+	}
+	a := d.Mover.Airframe()
+	a.Thrust = float32(*airThrust)
+	a.Lift = float32(*airLift)
+	a.Drag = float32(*airDrag)
+	a.StallSpeed = float32(*stallSpeed)
+	a.StallAngle = float32(*stallAngle)
+	a.Gravity = float32(*gravity)
+	d.Mover.SetAirframe(a)
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+//-autopilot-turn and -autopilot-speed are the PID gains of fly mode's G
+//autopilot as kp,ki,kd, -autopilot-max-turn and -autopilot-max-speed its
+//limits, e.g.
+//	go run . -autopilot-speed 0.5,0,0.5 -autopilot-max-speed 8
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var (
+	autopilotTurn     = flag.String("autopilot-turn", "2,0,0.1", "autopilot turn gains kp,ki,kd, radians per second per radian off the target")
+	autopilotSpeed    = flag.String("autopilot-speed", "1,0,0.2", "autopilot speed gains kp,ki,kd, units per second per unit still to go")
+	autopilotMaxTurn  = flag.Float64("autopilot-max-turn", 1.5, "autopilot top turn rate, radians per second")
+	autopilotMaxSpeed = flag.Float64("autopilot-max-speed", 4, "autopilot top speed, units per second")
+)
+
+//set up the autopilot as asked on the command line
+func setupAutopilot(ap *sim.Autopilot) error {
+
+	turn, err := parseGains(*autopilotTurn)
+	if err != nil {
+		return fmt.Errorf("-autopilot-turn: %v", err)
+	}
+	speed, err := parseGains(*autopilotSpeed)
+	if err != nil {
+		return fmt.Errorf("-autopilot-speed: %v", err)
+	}
+	if *autopilotMaxTurn <= 0 || *autopilotMaxSpeed <= 0 {
+		return fmt.Errorf("-autopilot-max-turn and -autopilot-max-speed must be above 0")
+	}
+	ap.Turn, ap.Speed = turn, speed
+	ap.MaxTurn, ap.MaxSpeed = float32(*autopilotMaxTurn), float32(*autopilotMaxSpeed)
+	return nil
+}
+
+//parse PID gains written kp,ki,kd, none below 0
+func parseGains(s string) (motion.Gains, error) {
+
+	v, err := parseVector(s)
+	if err != nil || v.X < 0 || v.Y < 0 || v.Z < 0 {
+		return motion.Gains{}, fmt.Errorf("want kp,ki,kd not below 0, got %q", s)
+	}
+	return motion.Gains{Kp: v.X, Ki: v.Y, Kd: v.Z}, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+//C, or -chase, has the camera follow the green gopher on a spring arm. The
+//orbit control still works, it orbits around her and the arm keeps where it
+//is put. -chase-offset, -chase-lag, -chase-rot-lag and -chase-look-ahead tune
+//it, e.g.
+//	go run . -chase -chase-offset 0,3,-8 -chase-lag 0.5
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Juuliuus/g3nmovedemo/sim"
+	"github.com/g3n/engine/math32"
+)
+
+var (
+	chase          = flag.Bool("chase", false, "start with the camera chasing the green gopher, C toggles it")
+	chaseOffset    = flag.String("chase-offset", "0,1.5,-5", "where the chase camera sits, right,up,forward of the gopher")
+	chaseLag       = flag.Float64("chase-lag", 0.3, "about how many seconds the chase camera takes to catch up")
+	chaseRotLag    = flag.Float64("chase-rot-lag", 0.15, "about how many seconds the chase camera takes to turn after")
+	chaseLookAhead = flag.Float64("chase-look-ahead", 0.5, "how many seconds ahead of the gopher's velocity the chase camera looks")
+)
+
+//set up the chase camera as asked on the command line
+func setupChase(d *sim.Demo) error {
+
+	offset, err := parseVector(*chaseOffset)
+	if err != nil {
+		return fmt.Errorf("-chase-offset: %v", err)
+	}
+	if *chaseLag < 0 || *chaseRotLag < 0 || *chaseLookAhead < 0 {
+		return fmt.Errorf("-chase-lag, -chase-rot-lag and -chase-look-ahead must not be below 0")
+	}
+	d.Chase.Offset = offset
+	d.Chase.PositionLag = float32(*chaseLag)
+	d.Chase.RotationLag = float32(*chaseRotLag)
+	d.Chase.LookAhead = float32(*chaseLookAhead)
+	d.SetChasing(*chase)
+	return nil
+}
+
+//parseVector reads "x,y,z"
+func parseVector(s string) (math32.Vector3, error) {
+
+	var v math32.Vector3
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("want x,y,z, got %q", s)
+	}
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil {
+			return v, fmt.Errorf("want x,y,z, got %q", s)
+		}
+		v.SetComponent(i, float32(f))
+	}
+	return v, nil
+}
+
+//the orbit control orbits around the chased node, not chasing it is left
+//alone so a pan sticks, and gets back the target it had before the chase
+func (gm *GameApp) orbitChase() {
+
+	chasing := demo.Chasing() && demo.Current() != demo.Camera
+	switch {
+	case chasing && !gm.orbitChasing:
+		gm.orbitTarget = gm.orbit.Target()
+	case !chasing && gm.orbitChasing:
+		gm.orbit.SetTarget(gm.orbitTarget)
+	}
+	gm.orbitChasing = chasing
+	if !chasing {
+		return
+	}
+	var target math32.Vector3
+	demo.Current().WorldPosition(&target)
+	gm.orbit.SetTarget(target)
+}
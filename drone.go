@@ -0,0 +1,36 @@
+package main
+
+//-max-tilt, -climb-rate and -drone-drag tune the drone mode, the eighth M
+//mode, e.g.
+//	go run . -max-tilt 0.6 -drone-drag 0.3
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var (
+	maxTilt   = flag.Float64("max-tilt", 0.35, "drone mode tilt at full stick, radians")
+	climbRate = flag.Float64("climb-rate", 3, "drone mode climb and sink speed, units per second")
+	droneDrag = flag.Float64("drone-drag", 0.5, "drone mode drag per unit of speed")
+)
+
+//set up drone mode's hovering as asked on the command line
+func setupHover(d *sim.Demo) error {
+
+	if *maxTilt <= 0 || *maxTilt >= 1.5 {
+		return fmt.Errorf("-max-tilt must be between 0 and 1.5")
+	}
+	if *climbRate < 0 || *droneDrag < 0 {
+		return fmt.Errorf("-climb-rate and -drone-drag must not be below 0")
+	}
+	h := d.Mover.Hover()
+	h.MaxTilt = float32(*maxTilt)
+	h.ClimbRate = float32(*climbRate)
+	h.Drag = float32(*droneDrag)
+	h.Gravity = float32(*gravity)
+	d.Mover.SetHover(h)
+	return nil
+}
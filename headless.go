@@ -0,0 +1,51 @@
+package main
+
+//-headless runs the movement routines without opening a window, handy on
+//machines without a GPU, e.g.  go run . -headless -keys m,z,z,y -steps 300
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var (
+	headless      = flag.Bool("headless", false, "run the movement simulation without a window and print the final node states")
+	headlessSteps = flag.Int("steps", 600, "headless: number of simulation ticks to run")
+	headlessKeys  = flag.String("keys", "", "headless: comma separated keys pressed before the run, e.g. m,z,z,ctrl+y")
+)
+
+//run the simulation headless and print where everything ended up
+func runHeadless() {
+
+	var states []sim.NodeState
+	d := sim.NewHeadless()
+	if err := setupDemo(d); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	switch {
+	case *replayFile != "":
+		r, err := sim.LoadReplay(*replayFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		states = d.Play(r)
+
+	default:
+		keys, err := sim.ParseKeys(*headlessKeys)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		states = d.Run(keys, *headlessSteps, float32(*simRate))
+	}
+
+	for _, ns := range states {
+		fmt.Println(ns)
+	}
+}
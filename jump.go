@@ -0,0 +1,34 @@
+package main
+
+//-gravity, -jump-speed, -coyote-time and -jump-buffer tune the platform
+//mode, the third M mode, e.g.
+//	go run . -gravity 20 -jump-speed 8 -coyote-time 0.15
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var (
+	gravity    = flag.Float64("gravity", 9.8, "platform, aircraft and drone mode gravity, units per second squared")
+	jumpSpeed  = flag.Float64("jump-speed", 5, "platform mode upward speed of a jump, units per second")
+	coyoteTime = flag.Float64("coyote-time", 0.1, "seconds after running off the floor a jump still works")
+	jumpBuffer = flag.Float64("jump-buffer", 0.1, "seconds a jump pressed in the air is kept until landing")
+)
+
+//set up platform mode's jumping as asked on the command line
+func setupJumping(d *sim.Demo) error {
+
+	if *gravity < 0 || *jumpSpeed < 0 || *coyoteTime < 0 || *jumpBuffer < 0 {
+		return fmt.Errorf("-gravity, -jump-speed, -coyote-time and -jump-buffer must not be below 0")
+	}
+	j := d.Mover.Jumping()
+	j.Gravity = float32(*gravity)
+	j.JumpSpeed = float32(*jumpSpeed)
+	j.CoyoteTime = float32(*coyoteTime)
+	j.JumpBuffer = float32(*jumpBuffer)
+	d.Mover.SetJumping(j)
+	return nil
+}
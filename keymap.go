@@ -0,0 +1,83 @@
+package main
+
+//-keymap rebinds the keys from a JSON file, only the actions listed change,
+//-print-keymap prints the bindings in effect, a good start for your own, e.g.
+//	go run . -print-keymap > my.keymap
+//	go run . -keymap my.keymap
+//-analog flies with held keys instead of presses
+
+import (
+	"flag"
+
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var (
+	keymapFile  = flag.String("keymap", "", "load key bindings from a JSON keymap file")
+	printKeymap = flag.Bool("print-keymap", false, "print the key bindings as a JSON keymap and exit")
+	analog      = flag.Bool("analog", false, "fly mode thrusts and turns while the keys are held")
+)
+
+//set up a demo the way the command line asks, windowed or headless
+func setupDemo(d *sim.Demo) error {
+
+	bindings, err := loadKeymap()
+	if err != nil {
+		return err
+	}
+	d.SetBindings(bindings)
+
+	if d.LookAtSlerp, err = lookAtSlerp(); err != nil {
+		return err
+	}
+	if err = setupSmoothing(d); err != nil {
+		return err
+	}
+	if err = setupJumping(d); err != nil {
+		return err
+	}
+	if err = setupWalking(d); err != nil {
+		return err
+	}
+	if err = setupThrusters(d); err != nil {
+		return err
+	}
+	if err = setupAirframe(d); err != nil {
+		return err
+	}
+	if err = setupHandling(d); err != nil {
+		return err
+	}
+	if err = setupHover(d); err != nil {
+		return err
+	}
+	if err = setupChase(d); err != nil {
+		return err
+	}
+	if err = setupSwitch(d); err != nil {
+		return err
+	}
+	if err = setupAutopilot(d.Autopilot); err != nil {
+		return err
+	}
+	if err = setupOpponents(d); err != nil {
+		return err
+	}
+	return setupTracker(d.Tracker)
+}
+
+//the key bindings asked for on the command line, the defaults without -keymap
+func loadKeymap() (*sim.Bindings, error) {
+
+	bindings := sim.DefaultBindings()
+	if *keymapFile != "" {
+		var err error
+		if bindings, err = sim.LoadBindings(*keymapFile); err != nil {
+			return nil, err
+		}
+	}
+	if *analog {
+		bindings.AnalogFly()
+	}
+	return bindings, nil
+}
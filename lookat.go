@@ -0,0 +1,52 @@
+package main
+
+//-slerp-time, -slerp-speed and -slerp-ease tune how the blue gopher turns to
+//her L LookAt targets, e.g.
+//	go run . -slerp-ease elastic -slerp-time 2
+//	go run . -slerp-ease ease-in-out -slerp-speed 1.5
+//-track-speed, -track-yaw-only and -track-pitch-limit tune her K tracking
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var (
+	slerpTime  = flag.Float64("slerp-time", 1, "seconds the blue gopher takes to turn with L")
+	slerpSpeed = flag.Float64("slerp-speed", 0, "if set, turn with L at most this many radians per second instead of -slerp-time")
+	slerpEase  = flag.String("slerp-ease", "linear", "easing of the L turn, one of "+strings.Join(motion.EasingNames(), ", "))
+
+	trackSpeed      = flag.Float64("track-speed", 1.5, "radians per second the blue gopher turns at most when tracking with K")
+	trackYawOnly    = flag.Bool("track-yaw-only", false, "the blue gopher only turns left and right when tracking")
+	trackPitchLimit = flag.Float64("track-pitch-limit", 0, "if set, radians above or below the horizon the blue gopher may look when tracking")
+)
+
+//the L slerp asked for on the command line
+func lookAtSlerp() (sim.SlerpSpec, error) {
+
+	ease, err := motion.ParseEasing(*slerpEase)
+	if err != nil {
+		return sim.SlerpSpec{}, err
+	}
+	if *slerpTime <= 0 || *slerpSpeed < 0 {
+		return sim.SlerpSpec{}, fmt.Errorf("-slerp-time must be above 0 and -slerp-speed not below")
+	}
+	return sim.SlerpSpec{Duration: float32(*slerpTime), Speed: float32(*slerpSpeed), Ease: ease}, nil
+}
+
+//set up the blue gopher's tracking as asked on the command line
+func setupTracker(tr *sim.Tracker) error {
+
+	if *trackSpeed <= 0 || *trackPitchLimit < 0 || *trackPitchLimit >= math.Pi/2 {
+		return fmt.Errorf("-track-speed must be above 0 and -track-pitch-limit at least 0 and below a right angle, %.4f", math.Pi/2)
+	}
+	tr.Speed = float32(*trackSpeed)
+	tr.YawOnly = *trackYawOnly
+	tr.PitchLimit = float32(*trackPitchLimit)
+	return nil
+}
@@ -0,0 +1,22 @@
+//Package motion holds the movement math of the g3n move demo as pure
+//functions over math32 vectors and quaternions, no nodes, no window, so it
+//can be imported by a game and tested on its own.
+package motion
+
+//June 2022, Julius Schoen / R.M. Spicer,  GPL 3 license
+//written to show how to move objects smoothly with the g3n game engine
+
+//This does an easein/easeout for motion and rotation, use the deltatime and
+//divide it to get longer ramp, multiply to get faster ramp, this is not my
+//creation, see link in code.
+//https://www.youtube.com/watch?v=qJq7I2DLGzI&list=PLW3Zl3wyJwWOpdhYedlD-yCB7WQoHf-My&index=13
+func Approach(goal, current, dtime float32) float32 {
+	difference := goal - current
+	if difference > dtime {
+		return current + dtime
+	}
+	if difference < -dtime {
+		return current - dtime
+	}
+	return goal
+}
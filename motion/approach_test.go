@@ -0,0 +1,36 @@
+package motion
+
+import "testing"
+
+func TestApproach(t *testing.T) {
+
+	tests := []struct {
+		name                 string
+		goal, current, dtime float32
+		want                 float32
+	}{
+		{"steps up", 1, 0, 0.25, 0.25},
+		{"steps down", -1, 0, 0.25, -0.25},
+		{"snaps when close", 1, 0.9, 0.25, 1},
+		{"snaps when close from above", 1, 1.1, 0.25, 1},
+		{"already there", 0.5, 0.5, 0.25, 0.5},
+		{"zero step stays", 1, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := Approach(tt.goal, tt.current, tt.dtime); got != tt.want {
+			t.Errorf("%s: Approach(%v, %v, %v) = %v, want %v", tt.name, tt.goal, tt.current, tt.dtime, got, tt.want)
+		}
+	}
+}
+
+func TestApproachReachesGoal(t *testing.T) {
+
+	current := float32(0)
+	for i := 0; i < 10; i++ {
+		current = Approach(1, current, 0.15)
+	}
+	if current != 1 {
+		t.Errorf("after 10 steps of 0.15 current = %v, want 1", current)
+	}
+}
@@ -0,0 +1,57 @@
+package motion
+
+import "github.com/g3n/engine/math32"
+
+//Axes is the convention of a node, which of its local axes is forward and
+//which is up. Meshes and cameras disagree: a properly oriented mesh faces its
+//positive Z axis while a camera looks down its negative Z axis.
+type Axes struct {
+	Forward, Up math32.Vector3
+}
+
+var (
+	//ObjectAxes is a properly oriented mesh, facing positive Z with Y up
+	ObjectAxes = Axes{Forward: math32.Vector3{X: 0, Y: 0, Z: 1}, Up: math32.Vector3{X: 0, Y: 1, Z: 0}}
+	//CameraAxes is a camera, looking down negative Z with Y up
+	CameraAxes = Axes{Forward: math32.Vector3{X: 0, Y: 0, Z: -1}, Up: math32.Vector3{X: 0, Y: 1, Z: 0}}
+)
+
+//Right is the local right axis, Forward x Up
+func (a *Axes) Right() math32.Vector3 {
+	var right math32.Vector3
+	right.CrossVectors(&a.Forward, &a.Up)
+	return *right.Normalize()
+}
+
+//Basis turns the local axes into world axes with the node's world quaternion,
+//whatever LookAt, roll or pitch the node went through. Up x Right = Forward,
+//i.e. Right, Up and the backward axis make the same right handed frame a
+//camera uses.
+func (a *Axes) Basis(q *math32.Quaternion) Basis {
+
+	var b Basis
+	b.Forward = a.Forward
+	b.Forward.ApplyQuaternion(q).Normalize()
+	b.Up = a.Up
+	b.Up.ApplyQuaternion(q).Normalize()
+	b.Right = a.Right()
+	b.Right.ApplyQuaternion(q).Normalize()
+	return b
+}
+
+//Rotate turns a node's local quaternion by pitch (nose up), yaw (nose left)
+//and roll (counterclockwise as seen from behind), in that order, each about
+//the node's own axes
+func (a *Axes) Rotate(q *math32.Quaternion, pitch, yaw, roll float32) {
+
+	var rot math32.Quaternion
+	right := a.Right()
+	back := a.Forward
+	back.Negate()
+
+	//about the right axis a positive angle lifts the nose
+	q.Multiply(rot.SetFromAxisAngle(&right, pitch))
+	q.Multiply(rot.SetFromAxisAngle(&a.Up, yaw))
+	//about the back axis a positive angle drops the left wing
+	q.Multiply(rot.SetFromAxisAngle(&back, roll))
+}
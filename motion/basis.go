@@ -0,0 +1,38 @@
+package motion
+
+import "github.com/g3n/engine/math32"
+
+//Basis is the forward, right and up axes of a node in world space, the
+//axes thrust is applied along when flying
+type Basis struct {
+	Forward, Right, Up math32.Vector3
+}
+
+//QuatBasis builds the flying basis straight from a node's world quaternion,
+//these are the node's true local axes whatever LookAt, roll or pitch it went
+//through. It is ObjectAxes.Basis: a properly oriented mesh faces its positive
+//Z axis, so Forward is local +Z, Up is local +Y and Right is local -X (the
+//mesh's own right hand). Use Axes.Basis for anything oriented otherwise.
+//
+//here is the gold nugget I got regarding flying / running around a room algorithm
+//see https://www.youtube.com/watch?v=FT7MShdqK6w&list=PLW3Zl3wyJwWOpdhYedlD-yCB7WQoHf-My&index=15
+//(the video builds these axes from Euler angles, that broke down once a LookAt
+//was involved, the quaternion has no such trouble)
+func QuatBasis(q *math32.Quaternion) Basis {
+	return ObjectAxes.Basis(q)
+}
+
+//Velocity composes a movement, X along Right, Y along Up and Z along
+//Forward, into a world space velocity
+func (b *Basis) Velocity(movement *math32.Vector3) math32.Vector3 {
+
+	forward, right, up := b.Forward, b.Right, b.Up
+
+	//apply the buffered (approach'd) movement to the vectors
+	forward.MultiplyScalar(movement.Z)
+	right.MultiplyScalar(movement.X)
+	up.MultiplyScalar(movement.Y)
+
+	//build velocity vector from everything above
+	return *forward.Add(&right).Add(&up)
+}
@@ -0,0 +1,172 @@
+package motion
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+const epsilon = 1e-4
+
+func near(a, b float32) bool {
+	return math32.Abs(a-b) < epsilon
+}
+
+//checkOrthonormal fails the test if the axes are not unit length and at right angles
+func checkOrthonormal(t *testing.T, name string, b *Basis) {
+	t.Helper()
+
+	for axis, v := range map[string]*math32.Vector3{"forward": &b.Forward, "right": &b.Right, "up": &b.Up} {
+		if !near(v.Length(), 1) {
+			t.Errorf("%s: %s has length %v, want 1", name, axis, v.Length())
+		}
+	}
+	if d := b.Forward.Dot(&b.Right); !near(d, 0) {
+		t.Errorf("%s: forward.right = %v, want 0", name, d)
+	}
+	if d := b.Forward.Dot(&b.Up); !near(d, 0) {
+		t.Errorf("%s: forward.up = %v, want 0", name, d)
+	}
+	if d := b.Right.Dot(&b.Up); !near(d, 0) {
+		t.Errorf("%s: right.up = %v, want 0", name, d)
+	}
+}
+
+//checkRightHanded fails the test if Up x Right is not Forward
+func checkRightHanded(t *testing.T, name string, b *Basis) {
+	t.Helper()
+
+	var cross math32.Vector3
+	cross.CrossVectors(&b.Up, &b.Right)
+	if !cross.AlmostEquals(&b.Forward, epsilon) {
+		t.Errorf("%s: up x right = %v, want forward %v", name, cross, b.Forward)
+	}
+}
+
+//orientations gives a spread of rotations, Euler sweeps including the
+//gimbal lock pitches, LookAt's in every direction and a rolled LookAt
+func orientations() map[string]math32.Quaternion {
+
+	qs := make(map[string]math32.Quaternion)
+	var q math32.Quaternion
+
+	angles := []float32{-math32.Pi, -2, -math32.Pi / 2, -0.7, 0, 0.3, math32.Pi / 4, math32.Pi / 2, 2.5}
+	for _, x := range angles {
+		for _, y := range angles {
+			for _, z := range angles {
+				q.SetFromEuler(math32.NewVector3(x, y, z))
+				qs[fmt.Sprintf("euler(%.2f,%.2f,%.2f)", x, y, z)] = q
+			}
+		}
+	}
+
+	var m math32.Matrix4
+	var roll math32.Quaternion
+	eye := math32.NewVector3(1, 2, 3)
+	up := math32.NewVector3(0, 1, 0)
+	targets := []*math32.Vector3{
+		math32.NewVector3(10, 2, 3), math32.NewVector3(-10, 2, 3),
+		math32.NewVector3(1, 2, 30), math32.NewVector3(1, 2, -30),
+		math32.NewVector3(1, 20, 3.5), math32.NewVector3(-4, -7, 9),
+	}
+	for i, target := range targets {
+		m.LookAt(eye, target, up)
+		q.SetFromRotationMatrix(&m)
+		qs[fmt.Sprintf("lookat %d", i)] = q
+
+		//LookAt followed by a roll and a pitch, what used to swap H and V
+		roll.SetFromAxisAngle(math32.NewVector3(0, 0, 1), 1.1)
+		q.Multiply(&roll)
+		roll.SetFromAxisAngle(math32.NewVector3(1, 0, 0), -0.6)
+		q.Multiply(&roll)
+		qs[fmt.Sprintf("lookat %d rolled", i)] = q
+	}
+
+	return qs
+}
+
+func TestQuatBasisOrthonormalRightHanded(t *testing.T) {
+
+	for name, q := range orientations() {
+		q := q
+		b := QuatBasis(&q)
+		checkOrthonormal(t, name, &b)
+		checkRightHanded(t, name, &b)
+	}
+}
+
+//thrust must be along the node's own local axes, whatever the orientation
+func TestQuatBasisIsLocalAxes(t *testing.T) {
+
+	for name, q := range orientations() {
+		q := q
+		b := QuatBasis(&q)
+
+		var m math32.Matrix4
+		m.MakeRotationFromQuaternion(&q)
+		localX := math32.NewVector3(m[0], m[1], m[2])
+		localY := math32.NewVector3(m[4], m[5], m[6])
+		localZ := math32.NewVector3(m[8], m[9], m[10])
+
+		if !b.Forward.AlmostEquals(localZ, epsilon) {
+			t.Errorf("%s: forward %v, want local Z %v", name, b.Forward, localZ)
+		}
+		if !b.Up.AlmostEquals(localY, epsilon) {
+			t.Errorf("%s: up %v, want local Y %v", name, b.Up, localY)
+		}
+		if !b.Right.AlmostEquals(localX.Negate(), epsilon) {
+			t.Errorf("%s: right %v, want local -X %v", name, b.Right, localX)
+		}
+	}
+}
+
+func TestCameraAxesBasis(t *testing.T) {
+
+	var q math32.Quaternion
+	q.SetIdentity()
+	b := CameraAxes.Basis(&q)
+	if !b.Forward.AlmostEquals(math32.NewVector3(0, 0, -1), epsilon) ||
+		!b.Right.AlmostEquals(math32.NewVector3(1, 0, 0), epsilon) ||
+		!b.Up.AlmostEquals(math32.NewVector3(0, 1, 0), epsilon) {
+		t.Errorf("unrotated camera basis %v, want forward -Z, right +X, up +Y", b)
+	}
+
+	for name, q := range orientations() {
+		q := q
+		b := CameraAxes.Basis(&q)
+		checkOrthonormal(t, name, &b)
+		checkRightHanded(t, name, &b)
+	}
+}
+
+func TestVelocityComposition(t *testing.T) {
+
+	b := Basis{
+		Forward: *math32.NewVector3(0, 0, 1),
+		Right:   *math32.NewVector3(1, 0, 0),
+		Up:      *math32.NewVector3(0, 1, 0),
+	}
+	movement := math32.NewVector3(1, 2, 3)
+	if got := b.Velocity(movement); !got.AlmostEquals(movement, epsilon) {
+		t.Errorf("axis aligned basis: velocity %v, want %v", got, movement)
+	}
+
+	//a rotated basis, the movement must come back out when projecting the velocity on each axis
+	q := orientations()["lookat 5 rolled"]
+	b = QuatBasis(&q)
+	v := b.Velocity(movement)
+	if !near(v.Dot(&b.Right), movement.X) || !near(v.Dot(&b.Up), movement.Y) || !near(v.Dot(&b.Forward), movement.Z) {
+		t.Errorf("rotated basis: velocity %v does not project back onto movement %v", v, movement)
+	}
+	if !near(v.Length(), movement.Length()) {
+		t.Errorf("rotated basis: |velocity| = %v, want %v", v.Length(), movement.Length())
+	}
+
+	//the basis itself must not be changed by composing a velocity
+	before := b
+	b.Velocity(movement)
+	if b != before {
+		t.Errorf("Velocity modified the basis")
+	}
+}
@@ -0,0 +1,99 @@
+package motion
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/g3n/engine/math32"
+)
+
+//Easing reshapes the fraction t (0 to 1) of a timed movement, it returns 0
+//at t 0 and 1 at t 1, in between it may speed up, slow down, or overshoot
+type Easing func(t float32) float32
+
+//Linear moves at a steady pace
+func Linear(t float32) float32 {
+	return t
+}
+
+//EaseIn starts slow and speeds up
+func EaseIn(t float32) float32 {
+	return t * t
+}
+
+//EaseOut starts fast and slows down to a stop
+func EaseOut(t float32) float32 {
+	return t * (2 - t)
+}
+
+//EaseInOut starts slow, speeds up and slows down to a stop
+func EaseInOut(t float32) float32 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+//Cubic is EaseInOut with a softer start and stop and a faster middle
+func Cubic(t float32) float32 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	u := 2*t - 2
+	return 1 + u*u*u/2
+}
+
+//Elastic overshoots the end and springs back and forth into place
+func Elastic(t float32) float32 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	return math32.Pow(2, -10*t)*math32.Sin((t*10-0.75)*2*math32.Pi/3) + 1
+}
+
+//easings by the names the demo's options use
+var easings = map[string]Easing{
+	"linear":      Linear,
+	"ease-in":     EaseIn,
+	"ease-out":    EaseOut,
+	"ease-in-out": EaseInOut,
+	"cubic":       Cubic,
+	"elastic":     Elastic,
+}
+
+//ParseEasing returns the easing of a name, see EasingNames
+func ParseEasing(name string) (Easing, error) {
+	e, ok := easings[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown easing %q, want one of %v", name, EasingNames())
+	}
+	return e, nil
+}
+
+//EasingNames returns the names ParseEasing knows
+func EasingNames() []string {
+	var names []string
+	for name := range easings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//MaxSlope returns how much faster than linear an easing gets at its fastest,
+//sampled, e.g. 2 for EaseInOut. A movement of length d eased this way and
+//allowed a top speed v needs d*MaxSlope/v seconds.
+func MaxSlope(e Easing) float32 {
+
+	const samples = 1000
+	max := float32(0)
+	prev := e(0)
+	for i := 1; i <= samples; i++ {
+		cur := e(float32(i) / samples)
+		if slope := math32.Abs(cur-prev) * samples; slope > max {
+			max = slope
+		}
+		prev = cur
+	}
+	return max
+}
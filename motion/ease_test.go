@@ -0,0 +1,63 @@
+package motion
+
+import "testing"
+
+func TestEasingEnds(t *testing.T) {
+
+	for _, name := range EasingNames() {
+		e, err := ParseEasing(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e(0) != 0 || !near(e(1), 1) {
+			t.Errorf("%s: e(0) = %v, e(1) = %v, want 0 and 1", name, e(0), e(1))
+		}
+	}
+
+	if _, err := ParseEasing("bouncy"); err == nil {
+		t.Errorf("unknown easing parsed")
+	}
+}
+
+func TestEasingShapes(t *testing.T) {
+
+	if EaseIn(0.25) >= 0.25 || EaseOut(0.25) <= 0.25 {
+		t.Errorf("ease-in should lag and ease-out lead linear at 0.25")
+	}
+	if !near(EaseInOut(0.5), 0.5) || !near(Cubic(0.5), 0.5) {
+		t.Errorf("in-out curves should be half way at half time")
+	}
+	if Cubic(0.1) >= EaseInOut(0.1) {
+		t.Errorf("cubic should start softer than ease-in-out")
+	}
+
+	overshoots := false
+	for i := 1; i < 100; i++ {
+		if Elastic(float32(i)/100) > 1 {
+			overshoots = true
+		}
+	}
+	if !overshoots {
+		t.Errorf("elastic never overshoots")
+	}
+}
+
+func TestMaxSlope(t *testing.T) {
+
+	tests := []struct {
+		name string
+		e    Easing
+		want float32
+	}{
+		{"linear", Linear, 1},
+		{"ease-in", EaseIn, 2},
+		{"ease-in-out", EaseInOut, 2},
+		{"cubic", Cubic, 3},
+	}
+
+	for _, tt := range tests {
+		if got := MaxSlope(tt.e); got < tt.want*0.99 || got > tt.want*1.01 {
+			t.Errorf("%s: MaxSlope = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package motion
+
+import "github.com/g3n/engine/math32"
+
+//LookAt returns the world rotation that turns these axes so Forward points
+//from eye at target and Up leans as close to up as it can. Unlike
+//Node.LookAt, which always turns a node's negative Z axis to the target like
+//a camera, this works for any forward axis, a mesh facing positive Z
+//included. Looking straight along up, Up leans to the current forward's
+//side instead, see LookAtFrom.
+func (a *Axes) LookAt(eye, target, up *math32.Vector3) math32.Quaternion {
+	var q math32.Quaternion
+	q.SetIdentity()
+	return a.LookAtFrom(&q, eye, target, up)
+}
+
+//LookAtFrom is LookAt for a node currently rotated by q, when the target is
+//straight along up (or straight down) any roll would do, it keeps the one
+//that changes least from q. If eye and target are the same q is returned.
+func (a *Axes) LookAtFrom(q *math32.Quaternion, eye, target, up *math32.Vector3) math32.Quaternion {
+
+	var forward, right, newUp math32.Vector3
+	forward.SubVectors(target, eye)
+	if forward.LengthSq() < 1e-12 {
+		return *q
+	}
+	forward.Normalize()
+
+	//Right = Forward x Up, see Axes.Right
+	right.CrossVectors(&forward, up)
+	if right.LengthSq() < 1e-12 {
+		//up is useless, keep the node's own right axis, flattened off forward
+		cur := a.Basis(q)
+		right = cur.Right
+		right.Sub(forward.Clone().MultiplyScalar(right.Dot(&forward)))
+		if right.LengthSq() < 1e-12 {
+			right.CrossVectors(&forward, &cur.Up)
+		}
+	}
+	right.Normalize()
+	newUp.CrossVectors(&right, &forward)
+
+	return a.FromBasis(&Basis{Forward: forward, Right: right, Up: newUp})
+}
+
+//FromBasis returns the world rotation that turns these axes into b, the
+//reverse of Basis, e.g. the rotation a camera needs to see the way a mesh
+//faces is CameraAxes.FromBasis of the mesh's basis. b must be orthonormal.
+func (a *Axes) FromBasis(b *Basis) math32.Quaternion {
+
+	//world axes = rotation * local axes, the local ones are orthonormal so the
+	//rotation is world * local transposed
+	var world, local, rot math32.Matrix4
+	localRight := a.Right()
+	world.MakeBasis(&b.Right, &b.Up, &b.Forward)
+	local.MakeBasis(&localRight, &a.Up, &a.Forward)
+	local.Transpose()
+	rot.MultiplyMatrices(&world, &local)
+
+	var q math32.Quaternion
+	q.SetFromRotationMatrix(&rot)
+	q.Normalize()
+	return q
+}
@@ -0,0 +1,104 @@
+package motion
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+//whatever the positions the forward axis must end up pointing at the target,
+//upright, for meshes and cameras alike
+func TestLookAtPointsForward(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(7))
+	pos := func() *math32.Vector3 {
+		return math32.NewVector3(rnd.Float32()*40-20, rnd.Float32()*40-20, rnd.Float32()*40-20)
+	}
+	yUp := math32.NewVector3(0, 1, 0)
+
+	for _, axes := range []Axes{ObjectAxes, CameraAxes} {
+		for i := 0; i < 200; i++ {
+			eye, target := pos(), pos()
+			q := axes.LookAt(eye, target, yUp)
+
+			want := *target
+			want.Sub(eye).Normalize()
+			b := axes.Basis(&q)
+			if !b.Forward.AlmostEquals(&want, 1e-4) {
+				t.Fatalf("axes %v, eye %v target %v: forward %v, want %v", axes, eye, target, b.Forward, want)
+			}
+			//no roll: right stays level, up stays on top
+			if !near(b.Right.Y, 0) || b.Up.Y < 0 {
+				t.Fatalf("axes %v, eye %v target %v: rolled, right %v up %v", axes, eye, target, b.Right, b.Up)
+			}
+			checkOrthonormal(t, "LookAt", &b)
+		}
+	}
+}
+
+//for a camera it must agree with g3n's own LookAt
+func TestLookAtMatchesCameraLookAt(t *testing.T) {
+
+	eye, target := math32.NewVector3(15, 4, -2), math32.NewVector3(0, 0, 0)
+	yUp := math32.NewVector3(0, 1, 0)
+
+	var m math32.Matrix4
+	var want math32.Quaternion
+	want.SetFromRotationMatrix(m.LookAt(eye, target, yUp))
+
+	got := CameraAxes.LookAt(eye, target, yUp)
+	if math32.Abs(got.Dot(&want)) < 1-1e-5 {
+		t.Errorf("camera LookAt %v, g3n's %v", got, want)
+	}
+}
+
+//looking straight up or down there is no roll to go by, it keeps the current one
+func TestLookAtStraightUp(t *testing.T) {
+
+	eye := math32.NewVector3(1, 2, 3)
+	yUp := math32.NewVector3(0, 1, 0)
+	var q math32.Quaternion
+	q.SetFromEuler(math32.NewVector3(0, 0.7, 0))
+	before := ObjectAxes.Basis(&q)
+
+	for _, target := range []*math32.Vector3{math32.NewVector3(1, 9, 3), math32.NewVector3(1, -9, 3)} {
+		look := ObjectAxes.LookAtFrom(&q, eye, target, yUp)
+		b := ObjectAxes.Basis(&look)
+		want := *target
+		want.Sub(eye).Normalize()
+		if !b.Forward.AlmostEquals(&want, 1e-4) {
+			t.Errorf("target %v: forward %v, want %v", target, b.Forward, want)
+		}
+		if !b.Right.AlmostEquals(&before.Right, 1e-4) {
+			t.Errorf("target %v: right %v, want it kept at %v", target, b.Right, before.Right)
+		}
+	}
+
+	same := ObjectAxes.LookAtFrom(&q, eye, eye, yUp)
+	if !same.Equals(&q) {
+		t.Errorf("looking at itself changed the rotation to %v", same)
+	}
+}
+
+//FromBasis undoes Basis, and a camera turned by it sees the way a mesh faces
+func TestFromBasis(t *testing.T) {
+
+	var q math32.Quaternion
+	q.SetFromEuler(math32.NewVector3(0.4, -1.3, 0.8))
+
+	for _, axes := range []Axes{ObjectAxes, CameraAxes} {
+		b := axes.Basis(&q)
+		got := axes.FromBasis(&b)
+		if math32.Abs(got.Dot(&q)) < 1-1e-5 {
+			t.Errorf("axes %v: FromBasis %v, want %v", axes, got, q)
+		}
+	}
+
+	mesh := ObjectAxes.Basis(&q)
+	camQ := CameraAxes.FromBasis(&mesh)
+	cam := CameraAxes.Basis(&camQ)
+	if !cam.Forward.AlmostEquals(&mesh.Forward, 1e-5) || !cam.Up.AlmostEquals(&mesh.Up, 1e-5) {
+		t.Errorf("camera sees along %v up %v, mesh faces %v up %v", cam.Forward, cam.Up, mesh.Forward, mesh.Up)
+	}
+}
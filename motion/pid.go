@@ -0,0 +1,52 @@
+package motion
+
+//Gains are the tuning of a PID controller: the output is Kp times the
+//error, plus Ki times its integral, plus Kd times how fast it changes
+type Gains struct {
+	Kp, Ki, Kd float32
+}
+
+//PID is a proportional integral derivative controller, it turns an error,
+//how far off something is, into a correction. It keeps the integral and the
+//last error, use one per controlled value and Reset it to start over.
+type PID struct {
+	Gains
+	//Limit clamps the output to -Limit..Limit, 0 is no limit. The integral
+	//stops growing while the output is clamped, so it does not wind up.
+	Limit float32
+
+	integral, last float32
+	started        bool
+}
+
+//Update returns the correction for err, dtime seconds after the last Update
+func (p *PID) Update(err, dtime float32) float32 {
+
+	//no derivative on the first error, there is nothing to tell it from
+	deriv := float32(0)
+	if p.started && dtime > 0 {
+		deriv = (err - p.last) / dtime
+	}
+	p.last, p.started = err, true
+
+	integral := p.integral + err*dtime
+	out := p.Kp*err + p.Ki*integral + p.Kd*deriv
+	if p.Limit > 0 && (out > p.Limit || out < -p.Limit) {
+		//integrating on would only push further past the limit
+		if out > p.Limit {
+			out = p.Limit
+		} else {
+			out = -p.Limit
+		}
+		if integral*err > 0 {
+			integral = p.integral
+		}
+	}
+	p.integral = integral
+	return out
+}
+
+//Reset forgets the integral and the last error
+func (p *PID) Reset() {
+	p.integral, p.last, p.started = 0, 0, false
+}
@@ -0,0 +1,49 @@
+package motion
+
+import "testing"
+
+func TestPIDTerms(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		gains Gains
+		errs  []float32
+		want  float32
+	}{
+		{"proportional", Gains{Kp: 2}, []float32{1.5}, 3},
+		{"integral", Gains{Ki: 1}, []float32{1, 1, 1, 1}, 1},
+		{"no derivative kick", Gains{Kd: 1}, []float32{5}, 0},
+		{"derivative", Gains{Kd: 1}, []float32{1, 0.75}, -1},
+	}
+
+	for _, tt := range tests {
+		p := PID{Gains: tt.gains}
+		var got float32
+		for _, e := range tt.errs {
+			got = p.Update(e, 0.25)
+		}
+		if d := got - tt.want; d > 1e-5 || d < -1e-5 {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+//a clamped output must not wind the integral up, once the error changes
+//sign the output follows at once
+func TestPIDLimitNoWindup(t *testing.T) {
+
+	p := PID{Gains: Gains{Kp: 1, Ki: 1}, Limit: 2}
+	for i := 0; i < 100; i++ {
+		if out := p.Update(10, 0.1); out != 2 {
+			t.Fatalf("tick %d: output %v, want clamped to 2", i, out)
+		}
+	}
+	if out := p.Update(-1, 0.1); out >= 0 {
+		t.Errorf("after the error went negative output %v, want below 0", out)
+	}
+
+	p.Reset()
+	if out := p.Update(0, 0.1); out != 0 {
+		t.Errorf("after Reset output %v, want 0", out)
+	}
+}
@@ -0,0 +1,185 @@
+package motion
+
+import (
+	"math"
+
+	"github.com/g3n/engine/math32"
+)
+
+//ApproachVec3 is Approach on each component, every component moves up to
+//step toward its goal
+func ApproachVec3(goal, current *math32.Vector3, step float32) math32.Vector3 {
+	return math32.Vector3{
+		X: Approach(goal.X, current.X, step),
+		Y: Approach(goal.Y, current.Y, step),
+		Z: Approach(goal.Z, current.Z, step),
+	}
+}
+
+//ApproachQuat turns current toward goal by at most maxAngle radians, the
+//rotation version of Approach
+func ApproachQuat(goal, current *math32.Quaternion, maxAngle float32) math32.Quaternion {
+
+	angle := QuatAngle(current, goal)
+	if angle <= maxAngle {
+		return *goal
+	}
+	//Slerp() works on the quat it is called on, a fraction of the way is maxAngle
+	q := *current
+	return *q.Slerp(goal, maxAngle/angle)
+}
+
+//QuatAngle returns the angle between two rotations, in radians
+func QuatAngle(a, b *math32.Quaternion) float32 {
+
+	//the rotation from a to b, its angle from atan2 rather than from the acos
+	//of a dot product, which float32 rounds to 0 for anything below a degree
+	d := *a
+	d.Conjugate().Multiply(b)
+	sin := math32.Sqrt(d.X*d.X + d.Y*d.Y + d.Z*d.Z)
+	return 2 * math32.Atan2(sin, math32.Abs(d.W))
+}
+
+//SmoothDamp moves current toward goal like a critically damped spring, it
+//gets there in about smoothTime seconds without overshooting, never faster
+//than maxSpeed (0 is no limit). velocity is the spring's state, keep it
+//between calls and zero it to start over.
+//see Game Programming Gems 4, chapter 1.10
+func SmoothDamp(goal, current float32, velocity *float32, smoothTime, maxSpeed, dtime float32) float32 {
+
+	g, c, v := math32.Vector3{X: goal}, math32.Vector3{X: current}, math32.Vector3{X: *velocity}
+	out := SmoothDampVec3(&g, &c, &v, smoothTime, maxSpeed, dtime)
+	*velocity = v.X
+	return out.X
+}
+
+//SmoothDampVec3 is SmoothDamp for a vector, maxSpeed limits the length of
+//the velocity
+func SmoothDampVec3(goal, current, velocity *math32.Vector3, smoothTime, maxSpeed, dtime float32) math32.Vector3 {
+
+	if dtime <= 0 {
+		return *current
+	}
+	smoothTime = math32.Max(smoothTime, 1e-4)
+	omega := 2 / smoothTime
+	x := omega * dtime
+	//a cheap and close enough exp(-x)
+	decay := 1 / (1 + x + 0.48*x*x + 0.235*x*x*x)
+
+	var change math32.Vector3
+	change.SubVectors(current, goal)
+	if maxSpeed > 0 {
+		if maxChange := maxSpeed * smoothTime; change.Length() > maxChange {
+			change.SetLength(maxChange)
+		}
+	}
+
+	//temp = (velocity + omega*change) * dtime
+	temp := change
+	temp.MultiplyScalar(omega).Add(velocity).MultiplyScalar(dtime)
+
+	//velocity = (velocity - omega*temp) * decay
+	step := temp
+	velocity.Sub(step.MultiplyScalar(omega)).MultiplyScalar(decay)
+
+	//out = goal' + (change + temp) * decay, goal' is the possibly clamped goal
+	var out math32.Vector3
+	out.SubVectors(current, &change)
+	change.Add(&temp).MultiplyScalar(decay)
+	out.Add(&change)
+
+	//do not overshoot
+	var toGoal, past math32.Vector3
+	toGoal.SubVectors(goal, current)
+	past.SubVectors(&out, goal)
+	if toGoal.Dot(&past) > 0 {
+		out = *goal
+		velocity.Zero()
+	}
+	return out
+}
+
+//SmoothDampQuat turns current toward goal like SmoothDamp, a rotation that
+//lags behind and catches up without overshooting. angularVelocity is the
+//spring's state in radians per second, keep it between calls.
+func SmoothDampQuat(goal, current *math32.Quaternion, angularVelocity *float32, smoothTime, dtime float32) math32.Quaternion {
+
+	angle := QuatAngle(current, goal)
+	if angle < 1e-6 {
+		*angularVelocity = 0
+		return *goal
+	}
+	//the spring closes the angle that is left, Slerp() goes the rest of the way
+	left := SmoothDamp(0, angle, angularVelocity, smoothTime, 0, dtime)
+	q := *current
+	return *q.Slerp(goal, 1-left/angle)
+}
+
+//Decay moves current toward goal by exponential decay: whatever the time
+//step, after t seconds exp(-rate*t) of the distance is left
+func Decay(goal, current, rate, dtime float32) float32 {
+	return goal + (current-goal)*exp(-rate*dtime)
+}
+
+//DecayVec3 is Decay for a vector
+func DecayVec3(goal, current *math32.Vector3, rate, dtime float32) math32.Vector3 {
+	k := exp(-rate * dtime)
+	return math32.Vector3{
+		X: goal.X + (current.X-goal.X)*k,
+		Y: goal.Y + (current.Y-goal.Y)*k,
+		Z: goal.Z + (current.Z-goal.Z)*k,
+	}
+}
+
+//Smoother eases a vector toward its goal one time step at a time. Some keep
+//state (a velocity), use one per smoothed value and Reset it on a stop.
+type Smoother interface {
+	Smooth(goal, current *math32.Vector3, dtime float32) math32.Vector3
+	Reset()
+}
+
+//Approacher is ApproachVec3 at Rate units per second
+type Approacher struct {
+	Rate float32
+}
+
+//Smooth moves current toward goal
+func (a *Approacher) Smooth(goal, current *math32.Vector3, dtime float32) math32.Vector3 {
+	return ApproachVec3(goal, current, a.Rate*dtime)
+}
+
+//Reset does nothing, an Approacher has no state
+func (a *Approacher) Reset() {}
+
+//Damper is SmoothDampVec3
+type Damper struct {
+	SmoothTime, MaxSpeed float32
+	Velocity             math32.Vector3
+}
+
+//Smooth moves current toward goal
+func (d *Damper) Smooth(goal, current *math32.Vector3, dtime float32) math32.Vector3 {
+	return SmoothDampVec3(goal, current, &d.Velocity, d.SmoothTime, d.MaxSpeed, dtime)
+}
+
+//Reset stops the spring
+func (d *Damper) Reset() {
+	d.Velocity.Zero()
+}
+
+//Decayer is DecayVec3 at Rate per second
+type Decayer struct {
+	Rate float32
+}
+
+//Smooth moves current toward goal
+func (d *Decayer) Smooth(goal, current *math32.Vector3, dtime float32) math32.Vector3 {
+	return DecayVec3(goal, current, d.Rate, dtime)
+}
+
+//Reset does nothing, a Decayer has no state
+func (d *Decayer) Reset() {}
+
+func exp(x float32) float32 {
+	return float32(math.Exp(float64(x)))
+}
@@ -0,0 +1,133 @@
+package motion
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+func TestApproachVec3(t *testing.T) {
+
+	got := ApproachVec3(math32.NewVector3(1, -1, 0.1), math32.NewVector3(0, 0, 0), 0.25)
+	if want := math32.NewVector3(0.25, -0.25, 0.1); !got.Equals(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestApproachQuat(t *testing.T) {
+
+	var from, to math32.Quaternion
+	from.SetIdentity()
+	to.SetFromEuler(math32.NewVector3(0, 1, 0))
+
+	q := ApproachQuat(&to, &from, 0.25)
+	if a := QuatAngle(&from, &q); !near(a, 0.25) {
+		t.Errorf("turned %v, want 0.25", a)
+	}
+	if q = ApproachQuat(&to, &q, 2); !q.Equals(&to) {
+		t.Errorf("close enough should snap to the goal, got %v", q)
+	}
+}
+
+//a critically damped spring settles without overshoot, at any frame rate
+func TestSmoothDamp(t *testing.T) {
+
+	for _, dtime := range []float32{1.0 / 30, 1.0 / 60, 1.0 / 144} {
+		current, velocity := float32(0), float32(0)
+		var atHalf float32
+		for elapsed := float32(0); elapsed < 2; elapsed += dtime {
+			current = SmoothDamp(10, current, &velocity, 0.3, 0, dtime)
+			if current > 10 {
+				t.Fatalf("dtime %v: overshot to %v", dtime, current)
+			}
+			if elapsed < 0.3 {
+				atHalf = current
+			}
+		}
+		if math32.Abs(current-10) > 1e-2 || math32.Abs(velocity) > 0.1 {
+			t.Errorf("dtime %v: ended at %v moving %v, want settled at 10", dtime, current, velocity)
+		}
+		//one smooth time in it is well on its way, not there
+		if atHalf < 5 || atHalf > 9.5 {
+			t.Errorf("dtime %v: at the smooth time %v, want between 5 and 9.5", dtime, atHalf)
+		}
+	}
+}
+
+func TestSmoothDampMaxSpeed(t *testing.T) {
+
+	goal := math32.NewVector3(100, 0, 0)
+	var current, velocity math32.Vector3
+	for i := 0; i < 60; i++ {
+		before := current
+		current = SmoothDampVec3(goal, &current, &velocity, 0.5, 4, 1.0/60)
+		if step := current.DistanceTo(&before); step > 4.0/60*1.01 {
+			t.Fatalf("tick %d: moved %v, max speed allows %v", i, step, 4.0/60)
+		}
+	}
+}
+
+//a damped turn gets there without turning past the goal
+func TestSmoothDampQuat(t *testing.T) {
+
+	var from, to math32.Quaternion
+	from.SetIdentity()
+	to.SetFromEuler(math32.NewVector3(0.5, 2, 0))
+
+	q, velocity := from, float32(0)
+	last := QuatAngle(&q, &to)
+	for i := 0; i < 120; i++ {
+		q = SmoothDampQuat(&to, &q, &velocity, 0.3, 1.0/60)
+		left := QuatAngle(&q, &to)
+		if left > last+1e-5 {
+			t.Fatalf("tick %d: turned away from the goal, %v rad left after %v", i, left, last)
+		}
+		last = left
+	}
+	if last > 1e-2 {
+		t.Errorf("after 2 s still %v rad off", last)
+	}
+}
+
+//decay is exact at any frame rate
+func TestDecay(t *testing.T) {
+
+	want := 10 - 10*exp(-3)
+	for _, dtime := range []float32{1.0 / 30, 1.0 / 60, 1.0 / 144} {
+		current := float32(0)
+		for i := 0; float32(i) < 1/dtime-0.5; i++ {
+			current = Decay(10, current, 3, dtime)
+		}
+		if math32.Abs(current-want) > 1e-3 {
+			t.Errorf("dtime %v: after 1 s %v, want %v", dtime, current, want)
+		}
+	}
+
+	v := DecayVec3(math32.NewVector3(2, 2, 2), math32.NewVector3(0, 4, 2), 100, 1)
+	if !v.AlmostEquals(math32.NewVector3(2, 2, 2), 1e-4) {
+		t.Errorf("DecayVec3 did not settle, %v", v)
+	}
+}
+
+//every Smoother gets there and Reset forgets the spring's velocity
+func TestSmoothers(t *testing.T) {
+
+	for _, s := range []Smoother{&Approacher{Rate: 1}, &Damper{SmoothTime: 0.2}, &Decayer{Rate: 10}} {
+		goal := math32.NewVector3(0.5, -0.2, 0.1)
+		var current math32.Vector3
+		for i := 0; i < 120; i++ {
+			current = s.Smooth(goal, &current, 1.0/60)
+		}
+		if !current.AlmostEquals(goal, 1e-3) {
+			t.Errorf("%T: ended at %v, want %v", s, current, goal)
+		}
+	}
+
+	d := &Damper{SmoothTime: 0.2}
+	var current math32.Vector3
+	current = d.Smooth(math32.NewVector3(1, 0, 0), &current, 1.0/60)
+	d.Reset()
+	if d.Velocity.Length() != 0 {
+		t.Errorf("Reset left velocity %v", d.Velocity)
+	}
+}
@@ -0,0 +1,322 @@
+package motion
+
+import (
+	"math/rand"
+
+	"github.com/g3n/engine/math32"
+)
+
+//Agent is what steering behaviours see of a mover, where it is and how fast
+//it goes, in units and seconds. The behaviours only say which velocity they
+//want, Steer gets there no quicker than MaxAccel.
+//see https://www.red3d.com/cwr/steer/
+type Agent struct {
+	Position, Velocity math32.Vector3
+	//MaxSpeed caps the speed, MaxAccel how quickly the velocity changes, 0
+	//is no limit
+	MaxSpeed, MaxAccel float32
+}
+
+//Steer turns the agent's velocity toward desired, by at most MaxAccel per
+//second, no faster than MaxSpeed, and moves it along for dtime seconds
+func (a *Agent) Steer(desired *math32.Vector3, dtime float32) {
+
+	var change math32.Vector3
+	change.SubVectors(desired, &a.Velocity)
+	if max := a.MaxAccel * dtime; a.MaxAccel > 0 && change.Length() > max {
+		change.SetLength(max)
+	}
+	a.Velocity.Add(&change)
+	if a.MaxSpeed > 0 && a.Velocity.Length() > a.MaxSpeed {
+		a.Velocity.SetLength(a.MaxSpeed)
+	}
+	step := a.Velocity
+	a.Position.Add(step.MultiplyScalar(dtime))
+}
+
+//Behaviour is a steering behaviour, it returns the velocity it wants the
+//agent to go at, ok is false when it has nothing to say, like a Flee with
+//nothing near. Behaviours compose, see Blend and Priority.
+type Behaviour interface {
+	Desired(a *Agent, dtime float32) (v math32.Vector3, ok bool)
+}
+
+//toward returns the velocity of length speed from from to to, zero if they
+//are the same point
+func toward(from, to *math32.Vector3, speed float32) math32.Vector3 {
+
+	var v math32.Vector3
+	v.SubVectors(to, from)
+	if v.LengthSq() < 1e-12 {
+		return math32.Vector3{}
+	}
+	v.SetLength(speed)
+	return v
+}
+
+//Seek goes flat out at Target
+type Seek struct {
+	Target *math32.Vector3
+}
+
+//Desired is always ok
+func (s *Seek) Desired(a *Agent, dtime float32) (math32.Vector3, bool) {
+	return toward(&a.Position, s.Target, a.MaxSpeed), true
+}
+
+//Flee goes flat out away from Target, once closer than Radius, 0 is always
+type Flee struct {
+	Target *math32.Vector3
+	Radius float32
+}
+
+//Desired is not ok further than Radius
+func (f *Flee) Desired(a *Agent, dtime float32) (math32.Vector3, bool) {
+
+	if f.Radius > 0 && a.Position.DistanceTo(f.Target) > f.Radius {
+		return math32.Vector3{}, false
+	}
+	return toward(f.Target, &a.Position, a.MaxSpeed), true
+}
+
+//Arrive seeks Target and slows down inside SlowRadius, so it stops there
+//without overshooting, within Radius it wants to stand still
+type Arrive struct {
+	Target             *math32.Vector3
+	SlowRadius, Radius float32
+}
+
+//Desired is always ok
+func (ar *Arrive) Desired(a *Agent, dtime float32) (math32.Vector3, bool) {
+
+	dist := a.Position.DistanceTo(ar.Target)
+	if dist <= ar.Radius {
+		return math32.Vector3{}, true
+	}
+	speed := a.MaxSpeed
+	if ar.SlowRadius > 0 && dist < ar.SlowRadius {
+		speed *= dist / ar.SlowRadius
+	}
+	return toward(&a.Position, ar.Target, speed), true
+}
+
+//predict returns where target will be by the time a gets there, looking
+//no more than max seconds ahead, 0 is no limit
+func predict(a, target *Agent, max float32) math32.Vector3 {
+
+	t := float32(0)
+	if a.MaxSpeed > 0 {
+		t = a.Position.DistanceTo(&target.Position) / a.MaxSpeed
+	}
+	if max > 0 {
+		t = math32.Min(t, max)
+	}
+	ahead := target.Velocity
+	ahead.MultiplyScalar(t).Add(&target.Position)
+	return ahead
+}
+
+//Pursue seeks where Target is going to be, not where it is, looking at most
+//MaxPrediction seconds ahead, 0 is no limit
+type Pursue struct {
+	Target        *Agent
+	MaxPrediction float32
+}
+
+//Desired is always ok
+func (p *Pursue) Desired(a *Agent, dtime float32) (math32.Vector3, bool) {
+	ahead := predict(a, p.Target, p.MaxPrediction)
+	return toward(&a.Position, &ahead, a.MaxSpeed), true
+}
+
+//Evade flees where Target is going to be, once it is closer than Radius, 0
+//is always, see Pursue
+type Evade struct {
+	Target                *Agent
+	MaxPrediction, Radius float32
+}
+
+//Desired is not ok while Target is further than Radius
+func (e *Evade) Desired(a *Agent, dtime float32) (math32.Vector3, bool) {
+
+	if e.Radius > 0 && a.Position.DistanceTo(&e.Target.Position) > e.Radius {
+		return math32.Vector3{}, false
+	}
+	ahead := predict(a, e.Target, e.MaxPrediction)
+	return toward(&ahead, &a.Position, a.MaxSpeed), true
+}
+
+//Wander roams about on the level: it seeks a point on a circle of Radius
+//Distance ahead, a point that moves round the circle by up to Jitter radians
+//per second at random. Rand makes it repeatable, nil is a fixed seed.
+type Wander struct {
+	Distance, Radius, Jitter float32
+	Rand                     *rand.Rand
+
+	angle float32
+}
+
+//Desired is always ok
+func (w *Wander) Desired(a *Agent, dtime float32) (math32.Vector3, bool) {
+
+	if w.Rand == nil {
+		w.Rand = rand.New(rand.NewSource(1))
+	}
+	w.angle += (2*w.Rand.Float32() - 1) * w.Jitter * dtime
+
+	//ahead is the level heading, +Z standing still
+	ahead := math32.Vector3{X: a.Velocity.X, Z: a.Velocity.Z}
+	if ahead.LengthSq() < 1e-12 {
+		ahead.Set(0, 0, 1)
+	}
+	ahead.SetLength(w.Distance).Add(&a.Position)
+	ahead.X += w.Radius * math32.Cos(w.angle)
+	ahead.Z += w.Radius * math32.Sin(w.angle)
+	ahead.Y = a.Position.Y
+	return toward(&a.Position, &ahead, a.MaxSpeed), true
+}
+
+//Obstacle is a sphere to keep out of, Center may move
+type Obstacle struct {
+	Center *math32.Vector3
+	Radius float32
+}
+
+//AvoidObstacles looks LookAhead seconds down the way the agent goes and
+//steers round the nearest obstacle in the way, past its edge with
+//Clearance to spare, the agent's own size
+type AvoidObstacles struct {
+	Obstacles            []Obstacle
+	LookAhead, Clearance float32
+}
+
+//Desired is not ok with nothing in the way, nor standing still
+func (av *AvoidObstacles) Desired(a *Agent, dtime float32) (math32.Vector3, bool) {
+
+	speed := a.Velocity.Length()
+	if speed < 1e-6 {
+		return math32.Vector3{}, false
+	}
+	dir := a.Velocity
+	dir.DivideScalar(speed)
+	reach := speed * av.LookAhead
+
+	var nearest *Obstacle
+	var nearestAlong float32
+	var side math32.Vector3
+	for i := range av.Obstacles {
+		ob := &av.Obstacles[i]
+		var rel math32.Vector3
+		rel.SubVectors(ob.Center, &a.Position)
+		along := rel.Dot(&dir)
+		size := ob.Radius + av.Clearance
+		if along < 0 || along > reach+size || nearest != nil && along >= nearestAlong {
+			continue
+		}
+		//how far the center is off the way ahead
+		lateral := dir
+		lateral.MultiplyScalar(along)
+		lateral.SubVectors(&rel, &lateral)
+		if lateral.Length() >= size {
+			continue
+		}
+		nearest, nearestAlong, side = ob, along, lateral
+	}
+	if nearest == nil {
+		return math32.Vector3{}, false
+	}
+
+	//aim past the edge on the side the way ahead already is, dead center
+	//any side will do
+	side.Negate()
+	if side.LengthSq() < 1e-12 {
+		side.CrossVectors(&dir, &math32.Vector3{X: 0, Y: 1, Z: 0})
+		if side.LengthSq() < 1e-12 {
+			side.Set(1, 0, 0)
+		}
+	}
+	side.SetLength(nearest.Radius + av.Clearance).Add(nearest.Center)
+	return toward(&a.Position, &side, a.MaxSpeed), true
+}
+
+//FollowPath seeks the Points one after the other, on to the next once
+//within Radius of one. It goes round again if Loop, else it arrives at the
+//last one slowing down inside SlowRadius.
+type FollowPath struct {
+	Points             []math32.Vector3
+	Radius, SlowRadius float32
+	Loop               bool
+
+	next int
+}
+
+//Next returns the index of the point it is heading for
+func (fp *FollowPath) Next() int {
+	return fp.next
+}
+
+//Reset heads for the first point again
+func (fp *FollowPath) Reset() {
+	fp.next = 0
+}
+
+//Desired is not ok without points
+func (fp *FollowPath) Desired(a *Agent, dtime float32) (math32.Vector3, bool) {
+
+	if len(fp.Points) == 0 {
+		return math32.Vector3{}, false
+	}
+	last := fp.next == len(fp.Points)-1
+	if !(last && !fp.Loop) && a.Position.DistanceTo(&fp.Points[fp.next]) <= fp.Radius {
+		fp.next = (fp.next + 1) % len(fp.Points)
+		last = fp.next == len(fp.Points)-1
+	}
+	if last && !fp.Loop {
+		arrive := Arrive{Target: &fp.Points[fp.next], SlowRadius: fp.SlowRadius}
+		return arrive.Desired(a, dtime)
+	}
+	return toward(&a.Position, &fp.Points[fp.next], a.MaxSpeed), true
+}
+
+//Weighted is a behaviour and how much it counts in a Blend
+type Weighted struct {
+	Behaviour Behaviour
+	Weight    float32
+}
+
+//Blend is the weighted average of the behaviours that have something to say
+type Blend []Weighted
+
+//Desired is not ok if none of them is
+func (bl Blend) Desired(a *Agent, dtime float32) (math32.Vector3, bool) {
+
+	var sum math32.Vector3
+	total := float32(0)
+	for _, w := range bl {
+		v, ok := w.Behaviour.Desired(a, dtime)
+		if !ok {
+			continue
+		}
+		sum.Add(v.MultiplyScalar(w.Weight))
+		total += w.Weight
+	}
+	if total <= 0 {
+		return math32.Vector3{}, false
+	}
+	return *sum.DivideScalar(total), true
+}
+
+//Priority is the first of the behaviours that has something to say, put
+//obstacle avoidance first and it only steers the others when it must
+type Priority []Behaviour
+
+//Desired is not ok if none of them is
+func (pr Priority) Desired(a *Agent, dtime float32) (math32.Vector3, bool) {
+
+	for _, b := range pr {
+		if v, ok := b.Desired(a, dtime); ok {
+			return v, true
+		}
+	}
+	return math32.Vector3{}, false
+}
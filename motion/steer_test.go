@@ -0,0 +1,187 @@
+package motion
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+const steerTick = float32(1.0 / 60)
+
+//run b on a for n ticks, calling each after every tick
+func steerTicks(a *Agent, b Behaviour, n int, each func(i int)) {
+	for i := 0; i < n; i++ {
+		v, _ := b.Desired(a, steerTick)
+		a.Steer(&v, steerTick)
+		if each != nil {
+			each(i)
+		}
+	}
+}
+
+func TestSeekFleeArrive(t *testing.T) {
+
+	target := math32.Vector3{X: 10}
+	a := &Agent{MaxSpeed: 2}
+
+	if v, ok := (&Seek{Target: &target}).Desired(a, steerTick); !ok || v.X != 2 || v.Y != 0 || v.Z != 0 {
+		t.Errorf("seek wants %v, want flat out at the target", v)
+	}
+	if v, ok := (&Flee{Target: &target}).Desired(a, steerTick); !ok || v.X != -2 {
+		t.Errorf("flee wants %v, want flat out away from the target", v)
+	}
+	if _, ok := (&Flee{Target: &target, Radius: 5}).Desired(a, steerTick); ok {
+		t.Errorf("flee further than its radius should have nothing to say")
+	}
+
+	//arrive gets there from flat out and stops, never passing the target
+	a.MaxAccel = 4
+	arrive := &Arrive{Target: &target, SlowRadius: 3, Radius: 0.01}
+	steerTicks(a, arrive, 20*60, func(i int) {
+		if a.Position.X > target.X+1e-3 {
+			t.Fatalf("tick %d: at %v, past the target", i, a.Position)
+		}
+	})
+	if d := a.Position.DistanceTo(&target); d > 0.05 || a.Velocity.Length() > 1e-3 {
+		t.Errorf("arrived %v off going %v, want stopped at the target", d, a.Velocity)
+	}
+}
+
+//pursuit leads a crossing target and catches it sooner than seeking it,
+//evading keeps further away than fleeing its position
+func TestPursueEvade(t *testing.T) {
+
+	catch := func(b func(target *Agent) Behaviour) int {
+		target := &Agent{Position: math32.Vector3{X: 10}, Velocity: math32.Vector3{Z: 1.5}}
+		a := &Agent{MaxSpeed: 2, MaxAccel: 8}
+		chase := b(target)
+		for i := 0; i < 60*60; i++ {
+			if a.Position.DistanceTo(&target.Position) < 0.1 {
+				return i
+			}
+			v, _ := chase.Desired(a, steerTick)
+			a.Steer(&v, steerTick)
+			target.Steer(&target.Velocity, steerTick)
+		}
+		return 60 * 60
+	}
+	seek := catch(func(target *Agent) Behaviour { return &Seek{Target: &target.Position} })
+	pursue := catch(func(target *Agent) Behaviour { return &Pursue{Target: target} })
+	if pursue >= seek {
+		t.Errorf("pursue caught up in %d ticks, seek in %d, want pursue quicker", pursue, seek)
+	}
+
+	//the chaser comes at it from the side, evading dodges ahead of it
+	chaser := &Agent{Position: math32.Vector3{X: -6, Z: 3}, Velocity: math32.Vector3{X: 3}}
+	a := &Agent{MaxSpeed: 2, MaxAccel: 8}
+	evade := &Evade{Target: chaser, Radius: 20}
+	steerTicks(a, evade, 120, func(int) { chaser.Steer(&chaser.Velocity, steerTick) })
+	if d := a.Position.DistanceTo(&chaser.Position); d < 2 {
+		t.Errorf("evader %v from the chaser, want well clear", d)
+	}
+	if _, ok := (&Evade{Target: chaser, Radius: 1}).Desired(&Agent{Position: math32.Vector3{X: 100}}, steerTick); ok {
+		t.Errorf("evade further than its radius should have nothing to say")
+	}
+}
+
+//wander stays on the level at full speed and does the same with the same seed
+func TestWander(t *testing.T) {
+
+	run := func() math32.Vector3 {
+		a := &Agent{Position: math32.Vector3{Y: 4}, MaxSpeed: 1, MaxAccel: 2}
+		w := &Wander{Distance: 2, Radius: 1, Jitter: 3, Rand: rand.New(rand.NewSource(7))}
+		steerTicks(a, w, 600, func(i int) {
+			if a.Position.Y != 4 {
+				t.Fatalf("tick %d: wandered off the level to %v", i, a.Position)
+			}
+		})
+		return a.Position
+	}
+	first, second := run(), run()
+	if !first.Equals(&second) {
+		t.Errorf("same seed ended at %v and %v", first, second)
+	}
+	if first.DistanceTo(&math32.Vector3{Y: 4}) < 1 {
+		t.Errorf("wandered only to %v", first)
+	}
+}
+
+//seeking through an obstacle, avoiding it first never gets inside it
+func TestAvoidObstacles(t *testing.T) {
+
+	center, target := math32.Vector3{Z: 5}, math32.Vector3{Z: 10}
+	obstacle := Obstacle{Center: &center, Radius: 1}
+	for _, offset := range []float32{0, 0.3, -0.3} {
+		a := &Agent{Position: math32.Vector3{X: offset}, MaxSpeed: 2, MaxAccel: 6}
+		b := Priority{&AvoidObstacles{Obstacles: []Obstacle{obstacle}, LookAhead: 1.5, Clearance: 0.3}, &Seek{Target: &target}}
+		steerTicks(a, b, 10*60, func(i int) {
+			if d := a.Position.DistanceTo(&center); d < obstacle.Radius {
+				t.Fatalf("offset %v tick %d: %v inside the obstacle", offset, i, d)
+			}
+		})
+		if d := a.Position.DistanceTo(&target); d > 0.2 {
+			t.Errorf("offset %v: ended %v from the target", offset, d)
+		}
+	}
+
+	a := &Agent{Velocity: math32.Vector3{X: 1}, MaxSpeed: 2}
+	if _, ok := (&AvoidObstacles{Obstacles: []Obstacle{obstacle}, LookAhead: 2}).Desired(a, steerTick); ok {
+		t.Errorf("an obstacle off to the side is not in the way")
+	}
+}
+
+//the path is followed point by point, round again when it loops
+func TestFollowPath(t *testing.T) {
+
+	points := []math32.Vector3{{X: 4}, {X: 4, Z: 4}, {Z: 4}}
+	fp := &FollowPath{Points: points, Radius: 0.5, SlowRadius: 2}
+	a := &Agent{MaxSpeed: 2, MaxAccel: 8}
+	seen := []int{fp.Next()}
+	steerTicks(a, fp, 20*60, func(int) {
+		if n := fp.Next(); n != seen[len(seen)-1] {
+			seen = append(seen, n)
+		}
+	})
+	if len(seen) != 3 || seen[1] != 1 || seen[2] != 2 {
+		t.Errorf("headed for %v, want 0, 1, 2", seen)
+	}
+	if d := a.Position.DistanceTo(&points[2]); d > 0.05 {
+		t.Errorf("stopped %v from the last point", d)
+	}
+
+	fp = &FollowPath{Points: points, Radius: 0.5, Loop: true}
+	a = &Agent{MaxSpeed: 2, MaxAccel: 8}
+	laps, was := 0, fp.Next()
+	steerTicks(a, fp, 30*60, func(int) {
+		if n := fp.Next(); n == 0 && was == 2 {
+			laps++
+		}
+		was = fp.Next()
+	})
+	if laps == 0 {
+		t.Errorf("a looping path never came round to the start again")
+	}
+}
+
+//a blend averages what its behaviours want, skipping those with nothing to
+//say, a priority takes the first with something to say
+func TestBlendPriority(t *testing.T) {
+
+	east, north := math32.Vector3{X: 10}, math32.Vector3{Z: 10}
+	a := &Agent{MaxSpeed: 1}
+	far := &Flee{Target: &east, Radius: 1}
+
+	v, ok := Blend{{&Seek{Target: &east}, 1}, {&Seek{Target: &north}, 3}, {far, 5}}.Desired(a, steerTick)
+	if !ok || math32.Abs(v.X-0.25) > 1e-6 || math32.Abs(v.Z-0.75) > 1e-6 {
+		t.Errorf("blend wants %v, want 0.25, 0, 0.75", v)
+	}
+	if _, ok := (Blend{{far, 1}}).Desired(a, steerTick); ok {
+		t.Errorf("a blend of nothing to say has nothing to say")
+	}
+
+	v, ok = Priority{far, &Seek{Target: &north}, &Seek{Target: &east}}.Desired(a, steerTick)
+	if !ok || v.Z != 1 {
+		t.Errorf("priority wants %v, want the first seek's", v)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+//-opponents starts the demo with the spheres and the blue gopher moving, as
+//U does, -opponent-speed makes them all quicker or slower, e.g.
+//	go run . -opponents -opponent-speed 1.5
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var (
+	opponents     = flag.Bool("opponents", false, "start with the spheres and the blue gopher moving, U toggles them")
+	opponentSpeed = flag.Float64("opponent-speed", 1, "times the opponents' top speeds")
+)
+
+//set up the moving opponents as asked on the command line
+func setupOpponents(d *sim.Demo) error {
+
+	if *opponentSpeed <= 0 {
+		return fmt.Errorf("-opponent-speed must be above 0")
+	}
+	for _, st := range d.Opponents {
+		st.Agent.MaxSpeed *= float32(*opponentSpeed)
+	}
+	d.SetOpponents(*opponents)
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+//-record saves every key of a session with the simulation tick it was applied
+//at, -replay plays such a file back, in the window or with -headless, e.g.
+//	go run . -record thrusters.replay
+//	go run . -headless -replay thrusters.replay
+
+import (
+	"flag"
+
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var (
+	recordFile = flag.String("record", "", "record the keys of this session to a replay file")
+	replayFile = flag.String("replay", "", "play back a replay file")
+)
+
+//start recording and/or playing back, as asked on the command line
+func (gm *GameApp) setupReplay(mg *moveGopher) {
+
+	if *replayFile != "" {
+		r, err := sim.LoadReplay(*replayFile)
+		if err != nil {
+			gm.Log.Fatal("Error loading replay: %s", err)
+		}
+		if r.Rate != float32(*simRate) {
+			//the recorded rate is the one that reproduces the session
+			mg.loop = sim.NewLoop(mg.Demo, r.Rate)
+		}
+		mg.loop.Play(r)
+		gm.Log.Info("Playing replay %s, %d keys over %d ticks", *replayFile, len(r.Events), r.Ticks)
+	}
+
+	if *recordFile != "" {
+		mg.loop.Record()
+		gm.Log.Info("Recording keys to %s", *recordFile)
+	}
+}
+
+//save the recording, if there is one
+func (gm *GameApp) saveReplay(mg *moveGopher) {
+
+	r := mg.loop.Recording()
+	if r == nil {
+		return
+	}
+	if err := r.Save(*recordFile); err != nil {
+		gm.Log.Error("Error saving replay: %s", err)
+		return
+	}
+	gm.Log.Info("Saved replay %s, %d keys over %d ticks", *recordFile, len(r.Events), r.Ticks)
+}
@@ -16,6 +16,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/Juuliuus/g3nmovedemo/sim"
 	"github.com/g3n/engine/animation"
 	"github.com/g3n/engine/app"
 	"github.com/g3n/engine/camera"
@@ -49,26 +50,24 @@ type GameApp struct {
 	DirData  string
 	ambLight *light.Ambient
 	grid     *helper.Grid
-}
 
-//Demo basic struct
-type moveGopher struct {
-	grid *helper.Grid
-	//our main character
-	gopher *core.Node
-	//not moved in render loop, but tied to quatSlerp LookAt's
-	soloGopher *core.Node
+	//walk mode has the mouse, and where the cursor was last
+	mouseLook, cursorSeen bool
+	cursorX, cursorY      float32
 
-	//supporting actors
-	vecVelocity, vecVelocityPaused                  math32.Vector3
-	vecMovement, vecMovementGoal, vecMovementPaused math32.Vector3
-	vecRotation, vecRotationGoal, vecRotationPaused math32.Vector3
+	//the orbit control pivots on the chased node, and the pivot it had
+	//before, panned or not, to go back to
+	orbitChasing bool
+	orbitTarget  math32.Vector3
+}
 
-	//special vector to demonstrate smooth changes in velocity
-	vecAppVelocity, vecAppVelocityGoal math32.Vector3
+//Demo basic struct, the movement state itself is the embedded sim.Demo
+type moveGopher struct {
+	*sim.Demo
+	//runs the Demo at a fixed time step
+	loop *sim.Loop
 
-	//used for slerp'ing
-	fromQuat, toQuat math32.Quaternion
+	grid *helper.Grid
 
 	//bit part players
 	sphere1, sphere2 *graphic.Mesh
@@ -81,54 +80,12 @@ type moveGopher struct {
 	mesh, solomesh core.INode
 }
 
-var (
-
-	//allows switching movement between gopher and camera
-	currentNode  *core.Node
-	nodeIsGopher bool //sad variable needed to deal with differences between objects and cameras
-
-	//used in quaternion slerping through a go routine
-	vecLookAt, vecLookAtLooker, vecLookAtTarget math32.Vector3
-	rotMatrix                                   math32.Matrix4
-
-	//these are the vectors that makes flying / looking where you're running possible
-	vecViewTmp, vecViewForward, vecViewRight, vecViewUp math32.Vector3
-
-	//controls the current movement mode, simple translation vs. flying
-	mvType, mvCnt int = 0, 0
-
-	//chooses three objects in order for blue gopher to LookAt
-	ToggleLookAtTarget int = -1
-)
-
 //save some garbage collection
-var (
-	usePos              math32.Vector3
-	flDifference, dtime = float32(0.0), float32(0.0)
-	vecUpHat            = math32.NewVector3(0, 1, 0)
-	//didn't use
-	//vecRightHat                        = math32.NewVector3(1, 0, 0)
-	//vecScreenHat                       = math32.NewVector3(0, 0, 1)
-	incRot, incLinear, incAcceleration = float32(0.0), float32(0.0), float32(2)
-	vecT1, vecT2, vecI, vec1, vec2     math32.Vector3
-)
-
-//"constant" vars
-var (
-	zeroVector   math32.Vector3 = *math32.NewVector3(0, 0, 0)
-	cameraVector math32.Vector3 = *math32.NewVector3(15, 4, -2)
-)
+var dtime = float32(0.0)
 
 const (
-	mvTranslate = iota
-	mvFly
-)
-
-const (
-	incrementRotTranslate, incrementRotFly = float32(0.02), float32(0.004)
-	incrementLinear                        = float32(0.005)
-	progName                               = "Movement demo for g3n"
-	execName                               = "g3nmovedemo"
+	progName = "Movement demo for g3n"
+	execName = "g3nmovedemo"
 )
 
 // CreateGame and return a pointer to it
@@ -152,6 +109,8 @@ func CreateGame() *GameApp {
 
 	// Subscribe window to events
 	game.Subscribe(window.OnKeyDown, game.onKeyDown)
+	game.Subscribe(window.OnKeyUp, game.onKeyUp)
+	game.Subscribe(window.OnCursor, game.onCursor)
 
 	game.Subscribe(window.OnWindowSize, func(evname string, ev interface{}) { game.OnWindowResize() })
 
@@ -164,19 +123,21 @@ func CreateGame() *GameApp {
 //Initialize the moveGopher demo
 func (mg *moveGopher) Initialize(gm *GameApp) {
 
+	mg.Demo = &sim.Demo{Camera: gm.Camera.GetNode(), Ship: gm.Ship}
+
 	//load main character
 	mg.loadGLTF(0, filepath.Join(gm.DirData, "gopher.glb"))
 	gm.Scene.Add(mg.mesh)
-	mg.gopher = mg.mesh.GetNode()
-	mg.gopher.SetScale(0.3, 0.3, 0.3)
-	mg.gopher.SetPosition(0, 0, 0)
+	mg.Gopher = mg.mesh.GetNode()
+	mg.Gopher.SetScale(0.3, 0.3, 0.3)
+	mg.Gopher.SetPosition(0, 0, 0)
 
 	//load our QuatSlerp model, a glb with an animation
 	mg.loadGLTF(1, filepath.Join(gm.DirData, "sologopher.glb"))
 	gm.Scene.Add(mg.solomesh)
-	mg.soloGopher = mg.solomesh.GetNode()
-	mg.soloGopher.SetScale(0.6, 0.6, 0.6)
-	mg.soloGopher.SetPosition(-5, 4, 3)
+	mg.SoloGopher = mg.solomesh.GetNode()
+	mg.SoloGopher.SetScale(0.6, 0.6, 0.6)
+	mg.SoloGopher.SetPosition(-5, 4, 3)
 
 	//load geometries
 	//texfile := a.DirData + "/images/"
@@ -194,6 +155,7 @@ func (mg *moveGopher) Initialize(gm *GameApp) {
 	mg.sphere1 = graphic.NewMesh(geom1, mat1)
 	mg.sphere1.SetPosition(-10, 4, 10)
 	gm.Scene.Add(mg.sphere1)
+	mg.Sphere1 = mg.sphere1.GetNode()
 
 	geom2 := geometry.NewSphere(2, 32, 32)
 	mat2 := material.NewStandard(&math32.Color{1, 1, 1})
@@ -201,6 +163,7 @@ func (mg *moveGopher) Initialize(gm *GameApp) {
 	mg.sphere2 = graphic.NewMesh(geom2, mat2)
 	mg.sphere2.SetPosition(0, 4, 10)
 	gm.Scene.Add(mg.sphere2)
+	mg.Sphere2 = mg.sphere2.GetNode()
 
 	//load fonts and setup message sprite
 
@@ -238,13 +201,14 @@ func (mg *moveGopher) Initialize(gm *GameApp) {
 	// Set background color to gray
 	gm.Gls().ClearColor(0.5, 0.5, 0.5, 1.0)
 
-	currentNode = mg.gopher
-	nodeIsGopher = true
 	//gm.AddEvent(evergogame.WsEVKeyD, mg.onKeyDown)
 
-	mvType = mvTranslate
 	//gm.Camera.Remove(gm.Ship)
-	mg.doReset(gm)
+	mg.Init()
+	if err := setupDemo(mg.Demo); err != nil {
+		gm.Log.Fatal("Error setting up the demo: %s", err)
+	}
+	mg.loop = sim.NewLoop(mg.Demo, float32(*simRate))
 }
 
 // check that the data directory exists
@@ -315,7 +279,7 @@ func (gm *GameApp) setupBasics() {
 	ah := helper.NewAxes(3)
 	gm.Scene.Add(ah)
 
-	gm.grid = helper.NewGrid(50, 1, math32.NewColor("darkgray"))
+	gm.grid = helper.NewGrid(sim.GridSize, 1, math32.NewColor("darkgray"))
 	gm.Scene.Add(gm.grid)
 
 	// Add white ambient light to the scene
@@ -403,12 +367,3 @@ func (mg *moveGopher) loadGLTF(which int, fpath string) {
 	}
 
 }
-
-//Re-set the up, right, screen normals
-func (mv *moveGopher) reset3DNormals() {
-	//These "constants" can be changed when they are used, re-set them
-	//for now I only use the vecUpHat....
-	//vecRightHat.Set(1, 0, 0)
-	vecUpHat.Set(0, 1, 0)
-	//vecScreenHat.Set(0, 0, 1)
-}
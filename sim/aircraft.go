@@ -0,0 +1,230 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/math32"
+)
+
+//Airframe tunes aircraft mode, see Controller.SetAirframe. The forces are
+//per unit of mass, i.e. accelerations, in units and seconds.
+type Airframe struct {
+	//Thrust is the acceleration at full throttle, along the nose
+	Thrust float32
+	//Lift is the lift per squared airspeed per radian of angle of attack,
+	//Drag the drag per squared airspeed, and InducedDrag the drag the lift
+	//costs, per squared airspeed and squared lift coefficient
+	Lift, Drag, InducedDrag float32
+	//SideForce is how hard the air pushes back on a sideslip, per airspeed,
+	//it keeps the craft flying where its nose points
+	SideForce float32
+	//StallAngle is the angle of attack, in radians, past which the wing
+	//stalls, StallSpeed the airspeed below which it does. StallPitch is how
+	//fast the nose drops in a stall, in radians per second.
+	StallAngle, StallSpeed, StallPitch float32
+	//PitchRate, YawRate and RollRate are how fast the controls turn the craft
+	//at full authority, in radians per second, the authority fades below
+	//StallSpeed
+	PitchRate, YawRate, RollRate float32
+	//Gravity pulls down along Y, in units per second squared
+	Gravity float32
+}
+
+//SetAirframe changes how aircraft mode flies
+func (c *Controller) SetAirframe(a Airframe) {
+	c.airframe = a
+}
+
+//Airframe returns how aircraft mode flies
+func (c *Controller) Airframe() Airframe {
+	return c.airframe
+}
+
+//Throttle returns aircraft mode's throttle, from 0 to 1
+func (c *Controller) Throttle() float32 {
+	return c.throttle
+}
+
+//Stalled is true while aircraft mode's wing is stalled
+func (c *Controller) Stalled() bool {
+	return c.stalled
+}
+
+//this sets the throttle and the control surfaces in aircraft mode, called
+//from OnAction. The controls are held keys by default, pressed they trim.
+func (c *Controller) Aircraft(a Action) bool {
+
+	switch a {
+
+	//Z opens the throttle and Ctrl Z closes it
+	case ActThrustForward:
+		c.throttle = math32.Min(c.throttle+throttleStep, 1)
+
+	case ActThrustBackward:
+		c.throttle = math32.Max(c.throttle-throttleStep, 0)
+
+	//same signs as Controller.Fly
+	case ActPitchUp:
+		c.vecRotationGoal.X += incrementRotTranslate
+
+	case ActPitchDown:
+		c.vecRotationGoal.X -= incrementRotTranslate
+
+	case ActYawLeft:
+		c.vecRotationGoal.Y += incrementRotTranslate
+
+	case ActYawRight:
+		c.vecRotationGoal.Y -= incrementRotTranslate
+
+	case ActRollLeft:
+		c.vecRotationGoal.Z += incrementRotTranslate
+
+	case ActRollRight:
+		c.vecRotationGoal.Z -= incrementRotTranslate
+
+	default:
+		return false
+	}
+	return true
+}
+
+//aircraft mode, called from Update: thrust builds airspeed, the wing's lift
+//holds the craft up as long as it flies fast enough and not too steep, and
+//banking turns it. The velocity is in world units per second.
+func (c *Controller) updateAircraft(dtime float32) {
+
+	if c.frozen {
+		return
+	}
+	node := c.mover.Node()
+	af := &c.airframe
+
+	//a held Z or Ctrl Z moves the throttle, Approach the controls
+	if c.moveOn[2] {
+		c.throttle = math32.Clamp(c.throttle+c.moveInput.Z*throttleRate*dtime, 0, 1)
+	}
+	speed := c.vecVelocity.Length()
+	authority := float32(1)
+	if af.StallSpeed > 0 {
+		authority = math32.Min(speed/af.StallSpeed, 1)
+	}
+	rates := math32.Vector3{X: af.PitchRate, Y: af.YawRate, Z: af.RollRate}
+	for i := 0; i < 3; i++ {
+		rot := c.vecRotationGoal.Component(i)
+		if c.rotOn[i] {
+			rot = c.rotInput.Component(i) * rates.Component(i)
+		}
+		c.vecRotation.SetComponent(i, motion.Approach(rot*authority, c.vecRotation.Component(i), controlRamp*dtime))
+	}
+	c.quatRot = node.Quaternion()
+	c.mover.Axes().Rotate(&c.quatRot, c.vecRotation.X*dtime, c.vecRotation.Y*dtime, c.vecRotation.Z*dtime)
+	node.SetQuaternionQuat(&c.quatRot)
+
+	node.WorldQuaternion(&c.quatView)
+	c.viewBasis = c.mover.Axes().Basis(&c.quatView)
+	b := &c.viewBasis
+
+	//the angle of attack is how far the nose is above the way it flies
+	var accel math32.Vector3
+	alpha := float32(0)
+	if speed > 1e-4 {
+		alpha = math32.Atan2(-c.vecVelocity.Dot(&b.Up), c.vecVelocity.Dot(&b.Forward))
+	}
+	//on the runway at the start of the tick, a craft put on it included
+	c.usePos = node.Position()
+	h, ok := c.ground(c.usePos.X, c.usePos.Z)
+	airborne := !ok || c.usePos.Y > h+1e-4 || c.vecVelocity.Y > 0
+	c.stalled = airborne && (speed < af.StallSpeed || math32.Abs(alpha) > af.StallAngle)
+
+	if speed > 1e-4 {
+		//past the stall angle the wing keeps only a little of its lift
+		cl := alpha
+		if math32.Abs(alpha) > af.StallAngle {
+			cl = stallLift * af.StallAngle
+			if alpha < 0 {
+				cl = -cl
+			}
+		}
+		dir := c.vecVelocity
+		dir.DivideScalar(speed)
+
+		//lift is square to the airflow, in the plane of the nose and the
+		//wings' up, it tilts with the bank
+		var lift math32.Vector3
+		lift.CrossVectors(&b.Right, &dir).Normalize().MultiplyScalar(af.Lift * speed * speed * cl)
+		accel.Add(&lift)
+
+		drag := dir
+		drag.MultiplyScalar(-(af.Drag + af.InducedDrag*cl*cl) * speed * speed)
+		accel.Add(&drag)
+
+		side := b.Right
+		side.MultiplyScalar(-af.SideForce * speed * c.vecVelocity.Dot(&b.Right))
+		accel.Add(&side)
+	}
+	thrust := b.Forward
+	thrust.MultiplyScalar(c.throttle * af.Thrust)
+	accel.Add(&thrust)
+	accel.Y -= af.Gravity
+
+	accel.MultiplyScalar(dtime)
+	c.vecVelocity.Add(&accel)
+
+	if airborne && !c.stalled {
+		c.coordinate(speed, dtime)
+	}
+	if c.stalled {
+		c.dropNose(dtime)
+	}
+
+	//the floor is a runway, the craft rolls on it until it lifts off
+	c.vecStep = c.vecVelocity
+	c.vecStep.MultiplyScalar(dtime)
+	c.usePos = node.Position()
+	was := c.usePos.Y
+	c.usePos.Add(&c.vecStep)
+	c.onGround = false
+	if h, ok := c.ground(c.usePos.X, c.usePos.Z); ok && c.usePos.Y <= h && was >= h {
+		c.usePos.Y = h
+		c.vecVelocity.Y = math32.Max(c.vecVelocity.Y, 0)
+		c.onGround = true
+	}
+	node.SetPositionVec(&c.usePos)
+}
+
+//a coordinated turn: the banked lift turns the flight path, the rudder turns
+//the nose with it at g tan(bank) / airspeed so it does not slip
+func (c *Controller) coordinate(speed, dtime float32) {
+
+	if speed < 1e-4 {
+		return
+	}
+	//rolled left the right wing is up, a positive bank turns left
+	bank := math32.Atan2(c.viewBasis.Right.Y, c.viewBasis.Up.Y)
+	bank = math32.Clamp(bank, -maxCoordinatedBank, maxCoordinatedBank)
+	c.turnWorld(&worldUp, c.airframe.Gravity*math32.Tan(bank)/speed*dtime)
+}
+
+//a stalled wing lets the nose fall toward the ground
+func (c *Controller) dropNose(dtime float32) {
+
+	down := math32.Vector3{X: 0, Y: -1, Z: 0}
+	var axis math32.Vector3
+	axis.CrossVectors(&c.viewBasis.Forward, &down)
+	if axis.LengthSq() < 1e-6 {
+		return
+	}
+	axis.Normalize()
+	//no further than straight down
+	angle := math32.Min(c.airframe.StallPitch*dtime, math32.Acos(math32.Clamp(c.viewBasis.Forward.Dot(&down), -1, 1)))
+	c.turnWorld(&axis, angle)
+}
+
+//turn the mover about a world axis, the mover is a child of the scene
+func (c *Controller) turnWorld(axis *math32.Vector3, angle float32) {
+
+	node := c.mover.Node()
+	c.quatRot.SetFromAxisAngle(axis, angle)
+	c.quatView = node.Quaternion()
+	c.quatView.MultiplyQuaternions(&c.quatRot, &c.quatView)
+	node.SetQuaternionQuat(&c.quatView)
+}
@@ -0,0 +1,73 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//full throttle runs it up the runway without lifting off until the nose
+//comes up, then it climbs out without stalling
+func TestAircraftTakesOff(t *testing.T) {
+
+	c := newInMode(Aircraft, ObjectMover(core.NewNode()))
+	c.OnKeyDown(KeyEvent{Key: KeyZ})
+	c.ticks(300)
+	if c.Throttle() != 1 {
+		t.Errorf("throttle at %v, want full", c.Throttle())
+	}
+	if p, v := c.Node().Position(), c.Velocity(); p.Y != 0 || v.Length() < c.Airframe().StallSpeed*2 {
+		t.Fatalf("on the runway at %v going %v, want fast along it", p, v.Length())
+	}
+
+	c.OnKeyDown(KeyEvent{Key: KeyP})
+	c.ticks(20)
+	c.OnKeyUp(KeyEvent{Key: KeyP})
+	c.ticks(300)
+	if y := c.Node().Position().Y; y < 10 || c.Stalled() {
+		t.Errorf("climbed to %v, stalled %v", y, c.Stalled())
+	}
+}
+
+//too slow the wing stalls and the nose drops, diving it flies again
+func TestAircraftStalls(t *testing.T) {
+
+	c := newInMode(Aircraft, ObjectMover(core.NewNode()))
+	c.Node().SetPosition(0, 200, 0)
+	c.ticks(1)
+	if !c.Stalled() {
+		t.Fatalf("not stalled standing still in the air")
+	}
+	c.ticks(59)
+	if fwd := c.Basis().Forward; fwd.Y > -0.7 {
+		t.Errorf("a second into the stall the nose is at %v, want dropped", fwd)
+	}
+	c.ticks(120)
+	if v := c.Velocity(); c.Stalled() {
+		t.Errorf("still stalled diving at %v", v.Length())
+	}
+}
+
+//banked it turns toward the low wing with the nose along the way it flies
+func TestAircraftCoordinatedTurn(t *testing.T) {
+
+	c := newInMode(Aircraft, ObjectMover(core.NewNode()))
+	c.Node().SetPosition(0, 200, 0)
+	c.vecVelocity.Set(0, 0, 13)
+	c.throttle = 1
+	c.ticks(60)
+
+	c.OnKeyDown(KeyEvent{Key: KeyR})
+	c.ticks(15)
+	c.OnKeyUp(KeyEvent{Key: KeyR})
+	c.ticks(120)
+
+	b, v := c.Basis(), c.Velocity()
+	if b.Forward.X < 0.3 {
+		t.Errorf("rolled left it heads %v, want turned toward +X", b.Forward)
+	}
+	if slip := math32.Abs(v.Dot(&b.Right)) / v.Length(); slip > 0.02 || c.Stalled() {
+		t.Errorf("slipping %v of the airspeed, stalled %v", slip, c.Stalled())
+	}
+}
@@ -0,0 +1,96 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//Autopilot is a Task that flies a fly mode Controller to a target and stops
+//it there. PID controllers set the goals the keys would set: the turn ones
+//steer the nose at the target and keep the wings level, the speed one slows
+//down as the target comes closer, so it arrives without overshooting. Run it
+//on the controller's node with a Scheduler, it is done once it has arrived,
+//or as soon as the controller leaves fly mode or steers another node.
+type Autopilot struct {
+	Controller *Controller
+	//Target puts the world position to fly to in its argument, every tick
+	Target func(pos *math32.Vector3)
+	//Turn makes a turn rate, in radians per second, of how many radians the
+	//nose is off the target, or the wings off level, no faster than MaxTurn
+	Turn    motion.Gains
+	MaxTurn float32
+	//Speed makes a speed, in units per second, of how far ahead the stop is,
+	//no faster than MaxSpeed
+	Speed    motion.Gains
+	MaxSpeed float32
+	//StandOff is how far short of the target it stops, Radius how close to
+	//there counts as arrived
+	StandOff, Radius float32
+
+	node                    *core.Node
+	pitch, yaw, roll, speed motion.PID
+	target, eye, dir        math32.Vector3
+}
+
+//PointTarget is a Target that stays at p
+func PointTarget(p math32.Vector3) func(pos *math32.Vector3) {
+	return func(pos *math32.Vector3) {
+		*pos = p
+	}
+}
+
+//Reset starts the flight over, run it again after a Reset
+func (ap *Autopilot) Reset() {
+	ap.node = nil
+	ap.pitch.Reset()
+	ap.yaw.Reset()
+	ap.roll.Reset()
+	ap.speed.Reset()
+}
+
+//Update sets the controller's goals for one tick, fly mode's goals are per
+//tick, the gains work per second
+func (ap *Autopilot) Update(dtime float32) bool {
+
+	c := ap.Controller
+	node := c.Node()
+	if ap.node == nil {
+		ap.node = node
+	}
+	if c.Mode() != Fly || node != ap.node {
+		return true
+	}
+
+	ap.Target(&ap.target)
+	node.WorldPosition(&ap.eye)
+	ap.dir.SubVectors(&ap.target, &ap.eye)
+	dist := ap.dir.Length()
+	left := dist - ap.StandOff
+	if left <= ap.Radius {
+		c.vecMovementGoal.Zero()
+		c.vecRotationGoal.Zero()
+		return true
+	}
+
+	//how far the target is off the nose, about the mover's own axes: yaw is
+	//left positive and pitch up, the roll brings the wings back level
+	b := c.Basis()
+	right, up, ahead := ap.dir.Dot(&b.Right), ap.dir.Dot(&b.Up), ap.dir.Dot(&b.Forward)
+	yawOff := math32.Atan2(-right, ahead)
+	pitchOff := math32.Atan2(up, math32.Sqrt(right*right+ahead*ahead))
+	rollOff := -math32.Atan2(b.Right.Y, b.Up.Y)
+
+	for _, p := range []*motion.PID{&ap.pitch, &ap.yaw, &ap.roll} {
+		p.Gains, p.Limit = ap.Turn, ap.MaxTurn
+	}
+	c.vecRotationGoal.Set(ap.pitch.Update(pitchOff, dtime)*dtime, ap.yaw.Update(yawOff, dtime)*dtime,
+		ap.roll.Update(rollOff, dtime)*dtime)
+
+	//only the way ahead counts, a target off to the side slows it down
+	//while it turns, one behind it stops it
+	ap.speed.Gains, ap.speed.Limit = ap.Speed, ap.MaxSpeed
+	want := ap.speed.Update(left*ahead/dist, dtime)
+	c.vecMovementGoal.Set(0, 0, math32.Max(want, 0)*dtime)
+	return false
+}
@@ -0,0 +1,106 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+//G flies the green gopher to the LookAt target, or anywhere else, and stops
+//her StandOff short of it without ever getting closer, whatever the smoothing
+func TestAutopilotArrives(t *testing.T) {
+
+	targets := []struct {
+		name string
+		at   math32.Vector3
+	}{
+		{"sphere1", math32.Vector3{X: -10, Y: 4, Z: 10}},
+		{"behind", math32.Vector3{X: 2, Y: -3, Z: -12}},
+		{"straight up", math32.Vector3{X: 0, Y: 15, Z: 0}},
+	}
+
+	for _, s := range []Smoothing{SmoothApproach, SmoothDamp, SmoothDecay} {
+		for i, tt := range targets {
+			d := NewHeadless()
+			d.SetSmoothing(s)
+			l := NewLoop(d, DefaultRate)
+			l.OnKeyDown(KeyEvent{Key: KeyM})
+			l.RunTicks(1)
+			if i == 0 {
+				l.OnKeyDown(KeyEvent{Key: KeyG})
+				l.RunTicks(1)
+			} else {
+				d.FlyTo(PointTarget(tt.at))
+			}
+			ap := d.Autopilot
+
+			closest := float32(math32.Infinity)
+			ticks := 0
+			for ; d.Autopiloting() && ticks < int(30*DefaultRate); ticks++ {
+				l.RunTicks(1)
+				p := d.Gopher.Position()
+				closest = math32.Min(closest, p.DistanceTo(&tt.at))
+			}
+			if d.Autopiloting() {
+				t.Errorf("%v %s: still flying after %d ticks", s, tt.name, ticks)
+				continue
+			}
+			if closest < ap.StandOff-1e-3 {
+				t.Errorf("%v %s: overshot to %v of the target, want no closer than %v", s, tt.name, closest, ap.StandOff)
+			}
+
+			//let go it comes to rest where it arrived
+			l.RunTicks(int(DefaultRate))
+			p := d.Gopher.Position()
+			if dist := p.DistanceTo(&tt.at); dist > ap.StandOff+ap.Radius+1e-3 || dist < ap.StandOff-1e-3 {
+				t.Errorf("%v %s: stopped %v from the target, want %v", s, tt.name, dist, ap.StandOff)
+			}
+			if v := d.Mover.Velocity(); v.Length() > 1e-5 {
+				t.Errorf("%v %s: still moving at %v", s, tt.name, v)
+			}
+		}
+	}
+}
+
+//the gains are live, tuned down it takes longer, and leaving fly mode or
+//pressing G again takes the controls back
+func TestAutopilotGainsAndHandBack(t *testing.T) {
+
+	flight := func(speed float32) int {
+		d := NewHeadless()
+		d.Mover.SetMode(Fly)
+		d.Autopilot.Speed.Kp = speed
+		d.FlyTo(PointTarget(math32.Vector3{X: 0, Y: 0, Z: 20}))
+		l := NewLoop(d, DefaultRate)
+		ticks := 0
+		for ; d.Autopiloting() && ticks < int(60*DefaultRate); ticks++ {
+			l.RunTicks(1)
+		}
+		return ticks
+	}
+	if quick, slow := flight(1), flight(0.25); slow <= quick {
+		t.Errorf("Kp 0.25 took %d ticks, Kp 1 %d, want longer", slow, quick)
+	}
+
+	d := NewHeadless()
+	l := NewLoop(d, DefaultRate)
+	l.OnKeyDown(KeyEvent{Key: KeyM})
+	l.OnKeyDown(KeyEvent{Key: KeyG})
+	l.RunTicks(10)
+	l.OnKeyDown(KeyEvent{Key: KeyG})
+	l.RunTicks(1)
+	if d.Autopiloting() {
+		t.Errorf("G again should take the controls back")
+	}
+
+	l.OnKeyDown(KeyEvent{Key: KeyG})
+	l.RunTicks(1)
+	if !d.Autopiloting() {
+		t.Fatalf("G should fly again")
+	}
+	d.Mover.SetMode(Walk)
+	l.RunTicks(1)
+	if d.Autopiloting() {
+		t.Errorf("the autopilot kept flying in walk mode")
+	}
+}
@@ -0,0 +1,496 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"sort"
+	"strings"
+)
+
+//Action is something a key can be bound to, e.g. "thrust_forward"
+type Action string
+
+//movement actions of translate mode
+const (
+	ActMoveXPlus    Action = "move_x_plus"
+	ActMoveXMinus   Action = "move_x_minus"
+	ActMoveYPlus    Action = "move_y_plus"
+	ActMoveYMinus   Action = "move_y_minus"
+	ActMoveZPlus    Action = "move_z_plus"
+	ActMoveZMinus   Action = "move_z_minus"
+	ActRotateXPlus  Action = "rotate_x_plus"
+	ActRotateXMinus Action = "rotate_x_minus"
+	ActRotateYPlus  Action = "rotate_y_plus"
+	ActRotateYMinus Action = "rotate_y_minus"
+	ActRotateZPlus  Action = "rotate_z_plus"
+	ActRotateZMinus Action = "rotate_z_minus"
+)
+
+//movement actions of fly mode
+const (
+	ActThrustForward  Action = "thrust_forward"
+	ActThrustBackward Action = "thrust_backward"
+	ActThrustLeft     Action = "thrust_left"
+	ActThrustRight    Action = "thrust_right"
+	ActThrustUp       Action = "thrust_up"
+	ActThrustDown     Action = "thrust_down"
+	ActPitchUp        Action = "pitch_up"
+	ActPitchDown      Action = "pitch_down"
+	ActYawLeft        Action = "yaw_left"
+	ActYawRight       Action = "yaw_right"
+	ActRollLeft       Action = "roll_left"
+	ActRollRight      Action = "roll_right"
+	ActSpin           Action = "spin"
+	ActSpinReverse    Action = "spin_reverse"
+	ActAutopilot      Action = "autopilot"
+)
+
+//movement actions of platform mode
+const (
+	ActRunForward  Action = "run_forward"
+	ActRunBackward Action = "run_backward"
+	ActStrafeLeft  Action = "strafe_left"
+	ActStrafeRight Action = "strafe_right"
+	ActTurnLeft    Action = "turn_left"
+	ActTurnRight   Action = "turn_right"
+	ActJump        Action = "jump"
+)
+
+//movement actions of walk mode, it also runs and strafes and turns like platform mode
+const (
+	ActLookUp   Action = "look_up"
+	ActLookDown Action = "look_down"
+)
+
+//movement actions of spaceship mode, its thrusters are fly mode's thrust and turns
+const (
+	ActFlightAssist Action = "flight_assist"
+)
+
+//movement actions of vehicle mode
+const (
+	ActThrottle   Action = "throttle"
+	ActBrake      Action = "brake"
+	ActSteerLeft  Action = "steer_left"
+	ActSteerRight Action = "steer_right"
+	ActHandbrake  Action = "handbrake"
+)
+
+//movement actions of more than one mode
+const (
+	ActAccelerate Action = "accelerate"
+	ActDecelerate Action = "decelerate"
+)
+
+//actions available in every mode
+const (
+	ActStopRotation    Action = "stop_rotation"
+	ActApproachPlus    Action = "approach_plus"
+	ActApproachMinus   Action = "approach_minus"
+	ActSlerpLookAt     Action = "slerp_lookat"
+	ActSnapLookAt      Action = "snap_lookat"
+	ActToggleTracking  Action = "toggle_tracking"
+	ActToggleOpponents Action = "toggle_opponents"
+	ActToggleChase     Action = "toggle_chase"
+	ActToggleMode      Action = "toggle_mode"
+	ActToggleNode      Action = "toggle_node"
+	ActReset           Action = "reset"
+	ActStop            Action = "stop"
+	ActPause           Action = "pause"
+	//these two are for the window, the simulation ignores them
+	ActFullscreen Action = "fullscreen"
+	ActQuit       Action = "quit"
+)
+
+//Global is the bindings section that applies in every mode, the others are
+//named after the mode, see ModeName
+const Global = "global"
+
+//continuousSection of a keymap lists per mode the actions that are held
+//rather than pressed, see Bindings.SetContinuous
+const continuousSection = "continuous"
+
+//defaultKeys are the bindings the demo has always had
+var defaultKeys = map[string]map[Action]string{
+	Global: {
+		ActStopRotation:    "b",
+		ActApproachPlus:    "d",
+		ActApproachMinus:   "e",
+		ActSlerpLookAt:     "l",
+		ActSnapLookAt:      "ctrl+l",
+		ActToggleTracking:  "k",
+		ActToggleOpponents: "u",
+		ActToggleChase:     "c",
+		ActToggleMode:      "m",
+		ActToggleNode:      "n",
+		ActReset:           "0,kp0,o",
+		ActStop:            "s",
+		ActPause:           "t",
+		ActFullscreen:      "f",
+		ActQuit:            "q",
+	},
+	"translate": {
+		ActMoveXPlus:    "x",
+		ActMoveXMinus:   "ctrl+x",
+		ActMoveYPlus:    "y",
+		ActMoveYMinus:   "ctrl+y",
+		ActMoveZPlus:    "z",
+		ActMoveZMinus:   "ctrl+z",
+		ActRotateXPlus:  "shift+x",
+		ActRotateXMinus: "shift+ctrl+x",
+		ActRotateYPlus:  "shift+y",
+		ActRotateYMinus: "shift+ctrl+y",
+		ActRotateZPlus:  "shift+z",
+		ActRotateZMinus: "shift+ctrl+z",
+		ActAccelerate:   "w",
+		ActDecelerate:   "ctrl+w",
+	},
+	"fly": {
+		ActThrustForward:  "z",
+		ActThrustBackward: "ctrl+z",
+		ActThrustLeft:     "h",
+		ActThrustRight:    "ctrl+h",
+		ActThrustUp:       "v",
+		ActThrustDown:     "ctrl+v",
+		ActPitchUp:        "p",
+		ActPitchDown:      "ctrl+p",
+		ActYawLeft:        "y",
+		ActYawRight:       "ctrl+y",
+		ActRollLeft:       "r",
+		ActRollRight:      "ctrl+r",
+		ActSpin:           "a",
+		ActSpinReverse:    "ctrl+a",
+		ActAutopilot:      "g",
+		ActAccelerate:     "w",
+		ActDecelerate:     "ctrl+w",
+	},
+	"platform": {
+		ActRunForward:  "z",
+		ActRunBackward: "ctrl+z",
+		ActStrafeLeft:  "h",
+		ActStrafeRight: "ctrl+h",
+		ActTurnLeft:    "y",
+		ActTurnRight:   "ctrl+y",
+		ActJump:        "space",
+	},
+	"walk": {
+		ActRunForward:  "z",
+		ActRunBackward: "ctrl+z",
+		ActStrafeLeft:  "h",
+		ActStrafeRight: "ctrl+h",
+		ActTurnLeft:    "y",
+		ActTurnRight:   "ctrl+y",
+		ActLookUp:      "p",
+		ActLookDown:    "ctrl+p",
+	},
+	"spaceship": {
+		ActThrustForward:  "z",
+		ActThrustBackward: "ctrl+z",
+		ActThrustLeft:     "h",
+		ActThrustRight:    "ctrl+h",
+		ActThrustUp:       "v",
+		ActThrustDown:     "ctrl+v",
+		ActPitchUp:        "p",
+		ActPitchDown:      "ctrl+p",
+		ActYawLeft:        "y",
+		ActYawRight:       "ctrl+y",
+		ActRollLeft:       "r",
+		ActRollRight:      "ctrl+r",
+		ActFlightAssist:   "a",
+	},
+	"aircraft": {
+		ActThrustForward:  "z",
+		ActThrustBackward: "ctrl+z",
+		ActPitchUp:        "p",
+		ActPitchDown:      "ctrl+p",
+		ActYawLeft:        "y",
+		ActYawRight:       "ctrl+y",
+		ActRollLeft:       "r",
+		ActRollRight:      "ctrl+r",
+	},
+	"vehicle": {
+		ActThrottle:   "z",
+		ActBrake:      "ctrl+z",
+		ActSteerLeft:  "y",
+		ActSteerRight: "ctrl+y",
+		ActHandbrake:  "space",
+	},
+	"drone": {
+		ActThrustForward:  "z",
+		ActThrustBackward: "ctrl+z",
+		ActThrustLeft:     "h",
+		ActThrustRight:    "ctrl+h",
+		ActThrustUp:       "v",
+		ActThrustDown:     "ctrl+v",
+		ActPitchUp:        "p",
+		ActPitchDown:      "ctrl+p",
+		ActYawLeft:        "y",
+		ActYawRight:       "ctrl+y",
+		ActRollLeft:       "r",
+		ActRollRight:      "ctrl+r",
+	},
+}
+
+//defaultContinuous are the actions held rather than pressed by default,
+//running, walking and turning on foot are held like in any game, and a
+//spaceship's thrusters fire, an aircraft's controls move, a car's pedals
+//and handbrake work and a drone's sticks are pushed while held
+var defaultContinuous = map[string][]Action{
+	"platform": {ActRunForward, ActRunBackward, ActStrafeLeft, ActStrafeRight, ActTurnLeft, ActTurnRight},
+	"walk":     {ActRunForward, ActRunBackward, ActStrafeLeft, ActStrafeRight, ActTurnLeft, ActTurnRight, ActLookUp, ActLookDown},
+	"spaceship": {ActThrustForward, ActThrustBackward, ActThrustLeft, ActThrustRight, ActThrustUp, ActThrustDown,
+		ActPitchUp, ActPitchDown, ActYawLeft, ActYawRight, ActRollLeft, ActRollRight},
+	"aircraft": {ActThrustForward, ActThrustBackward, ActPitchUp, ActPitchDown, ActYawLeft, ActYawRight, ActRollLeft, ActRollRight},
+	"vehicle":  {ActThrottle, ActBrake, ActSteerLeft, ActSteerRight, ActHandbrake},
+	"drone": {ActThrustForward, ActThrustBackward, ActThrustLeft, ActThrustRight, ActThrustUp, ActThrustDown,
+		ActPitchUp, ActPitchDown, ActYawLeft, ActYawRight, ActRollLeft, ActRollRight},
+}
+
+//Bindings maps keys to actions, per mode plus a Global section. An action
+//is an impulse, done once per key press, unless it is set continuous.
+type Bindings struct {
+	sections   map[string]map[Action][]KeyEvent
+	continuous map[string]map[Action]bool
+}
+
+//DefaultBindings returns the demo's own key bindings
+func DefaultBindings() *Bindings {
+
+	b := &Bindings{
+		sections:   make(map[string]map[Action][]KeyEvent),
+		continuous: make(map[string]map[Action]bool),
+	}
+	for section, actions := range defaultKeys {
+		b.sections[section] = make(map[Action][]KeyEvent)
+		for a, keys := range actions {
+			kevs, err := ParseKeys(keys)
+			if err != nil {
+				panic(err)
+			}
+			b.sections[section][a] = kevs
+		}
+	}
+	for section, actions := range defaultContinuous {
+		for _, a := range actions {
+			if err := b.SetContinuous(section, a, true); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return b
+}
+
+//Lookup finds the action of a key, first in the section of the mode, then
+//in Global. A binding matches when its key is pressed with at least its
+//modifiers, of several matches the one with the most modifiers wins, so
+//with the defaults Shift-Ctrl-L still is the Ctrl-L snap LookAt.
+func (b *Bindings) Lookup(section string, kev KeyEvent) (Action, bool) {
+
+	if a, ok := b.lookup(section, kev); ok {
+		return a, true
+	}
+	return b.lookup(Global, kev)
+}
+
+func (b *Bindings) lookup(section string, kev KeyEvent) (Action, bool) {
+
+	best, bestMods := Action(""), -1
+	for a, kevs := range b.sections[section] {
+		for _, k := range kevs {
+			if k.Key != kev.Key || k.Mods&^kev.Mods != 0 {
+				continue
+			}
+			n := bits.OnesCount(uint(k.Mods))
+			if n > bestMods || n == bestMods && a < best {
+				best, bestMods = a, n
+			}
+		}
+	}
+	return best, bestMods >= 0
+}
+
+//Keys returns the keys bound to an action in a section
+func (b *Bindings) Keys(section string, a Action) []KeyEvent {
+	return b.sections[section][a]
+}
+
+//Continuous is true if the action is held rather than pressed in a section
+func (b *Bindings) Continuous(section string, a Action) bool {
+	return b.continuous[section][a]
+}
+
+//SetContinuous makes an action continuous or an impulse again. A continuous
+//action pushes its axis for as long as its key is held, like a stick, and
+//lets go when the key comes up. Only the thrust, turn and move actions of
+//a mode can be continuous.
+func (b *Bindings) SetContinuous(section string, a Action, on bool) error {
+
+	if _, ok := axisActions[section][a]; !ok {
+		return fmt.Errorf("keymap: %s %s can not be continuous", section, a)
+	}
+	if b.continuous[section] == nil {
+		b.continuous[section] = make(map[Action]bool)
+	}
+	if on {
+		b.continuous[section][a] = true
+	} else {
+		delete(b.continuous[section], a)
+	}
+	return nil
+}
+
+//AnalogFly is the "analog" control style for fly mode: all thrusts and
+//turns are continuous, held keys ramp them up, released they ease off
+func (b *Bindings) AnalogFly() {
+	for a := range axisActions["fly"] {
+		b.SetContinuous("fly", a, true)
+	}
+}
+
+//Read changes the bindings from a JSON keymap, only the actions listed are
+//changed, every other action keeps its binding. The "continuous" section
+//lists the continuous actions of a mode, all others of that mode become
+//impulses. A keymap looks like
+//
+//	{
+//	  "global": { "toggle_node": ["j"] },
+//	  "fly": { "thrust_forward": ["i", "kp0"], "thrust_backward": ["ctrl+i"] },
+//	  "continuous": { "fly": ["thrust_forward", "thrust_backward"] }
+//	}
+func (b *Bindings) Read(r io.Reader) error {
+
+	var keymap map[string]json.RawMessage
+	dec := json.NewDecoder(r)
+	if err := dec.Decode(&keymap); err != nil {
+		return fmt.Errorf("keymap: %v", err)
+	}
+
+	for section, raw := range keymap {
+		if section == continuousSection {
+			if err := b.readContinuous(raw); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var actions map[Action][]string
+		if err := json.Unmarshal(raw, &actions); err != nil {
+			return fmt.Errorf("keymap: %s: %v", section, err)
+		}
+		known, ok := b.sections[section]
+		if !ok {
+			return fmt.Errorf("keymap: unknown section %q", section)
+		}
+		for a, keys := range actions {
+			if _, ok := known[a]; !ok {
+				return fmt.Errorf("keymap: unknown action %q in section %q", a, section)
+			}
+			kevs, err := ParseKeys(strings.Join(keys, ","))
+			if err != nil {
+				return fmt.Errorf("keymap: %s %s: %v", section, a, err)
+			}
+			known[a] = kevs
+		}
+	}
+	return b.check()
+}
+
+func (b *Bindings) readContinuous(raw json.RawMessage) error {
+
+	var continuous map[string][]Action
+	if err := json.Unmarshal(raw, &continuous); err != nil {
+		return fmt.Errorf("keymap: %s: %v", continuousSection, err)
+	}
+	for section, actions := range continuous {
+		if _, ok := axisActions[section]; !ok {
+			return fmt.Errorf("keymap: %s: mode %q has no continuous actions", continuousSection, section)
+		}
+		b.continuous[section] = nil
+		for _, a := range actions {
+			if err := b.SetContinuous(section, a, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//check that no key is bound to two actions of one section
+func (b *Bindings) check() error {
+
+	for section, actions := range b.sections {
+		seen := make(map[KeyEvent]Action)
+		for _, a := range sortedActions(actions) {
+			for _, kev := range actions[a] {
+				if other, ok := seen[kev]; ok {
+					return fmt.Errorf("keymap: %s: %v is bound to both %s and %s", section, kev, other, a)
+				}
+				seen[kev] = a
+			}
+		}
+	}
+	return nil
+}
+
+//Write saves the bindings as a JSON keymap, a good start for your own
+func (b *Bindings) Write(w io.Writer) error {
+
+	keymap := make(map[string]interface{})
+	for section, actions := range b.sections {
+		keys := make(map[Action][]string)
+		for a, kevs := range actions {
+			keys[a] = []string{}
+			for _, kev := range kevs {
+				keys[a] = append(keys[a], kev.String())
+			}
+		}
+		keymap[section] = keys
+	}
+
+	continuous := make(map[string][]Action)
+	for section := range axisActions {
+		continuous[section] = []Action{}
+		for _, a := range sortedActions(b.sections[section]) {
+			if b.Continuous(section, a) {
+				continuous[section] = append(continuous[section], a)
+			}
+		}
+	}
+	keymap[continuousSection] = continuous
+
+	//encoding/json sorts map keys, so the file is stable
+	data, err := json.MarshalIndent(keymap, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+//LoadBindings returns the default bindings changed by a JSON keymap file
+func LoadBindings(fpath string) (*Bindings, error) {
+
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := DefaultBindings()
+	if err := b.Read(f); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func sortedActions(actions map[Action][]KeyEvent) []Action {
+	var as []Action
+	for a := range actions {
+		as = append(as, a)
+	}
+	sort.Slice(as, func(i, j int) bool { return as[i] < as[j] })
+	return as
+}
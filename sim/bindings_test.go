@@ -0,0 +1,164 @@
+package sim
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+//the defaults must do what the hard coded keys always did
+func TestDefaultBindings(t *testing.T) {
+
+	b := DefaultBindings()
+	tests := []struct {
+		section string
+		key     string
+		want    Action
+	}{
+		{"translate", "x", ActMoveXPlus},
+		{"translate", "ctrl+x", ActMoveXMinus},
+		{"translate", "shift+y", ActRotateYPlus},
+		{"translate", "ctrl+shift+z", ActRotateZMinus},
+		{"translate", "ctrl+w", ActDecelerate},
+		{"fly", "z", ActThrustForward},
+		{"fly", "ctrl+h", ActThrustRight},
+		{"fly", "y", ActYawLeft},
+		{"fly", "ctrl+r", ActRollRight},
+		{"fly", "shift+p", ActPitchUp},
+		{"fly", "n", ActToggleNode},
+		{"translate", "l", ActSlerpLookAt},
+		{"translate", "ctrl+l", ActSnapLookAt},
+		{"fly", "ctrl+shift+l", ActSnapLookAt},
+		{"fly", "kp0", ActReset},
+		{"fly", "alt+t", ActPause},
+		{"platform", "space", ActJump},
+		{"platform", "ctrl+y", ActTurnRight},
+		{"spaceship", "ctrl+v", ActThrustDown},
+		{"spaceship", "a", ActFlightAssist},
+		{"aircraft", "ctrl+r", ActRollRight},
+		{"vehicle", "space", ActHandbrake},
+		{"fly", "g", ActAutopilot},
+		{"drone", "r", ActRollLeft},
+		{"vehicle", "u", ActToggleOpponents},
+	}
+
+	for _, tt := range tests {
+		kev, err := ParseKey(tt.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := b.Lookup(tt.section, kev); !ok || got != tt.want {
+			t.Errorf("%s %s: got %q, want %q", tt.section, tt.key, got, tt.want)
+		}
+	}
+
+	if a, ok := b.Lookup("fly", KeyEvent{Key: KeyJ}); ok {
+		t.Errorf("j is not bound, got %q", a)
+	}
+	if !b.Continuous("platform", ActRunForward) || b.Continuous("platform", ActJump) || b.Continuous("fly", ActThrustForward) {
+		t.Errorf("running on foot should be held, the rest pressed")
+	}
+}
+
+func TestReadBindings(t *testing.T) {
+
+	b := DefaultBindings()
+	err := b.Read(strings.NewReader(`{
+		"fly": { "thrust_forward": ["i", "ctrl+kp0"] },
+		"global": { "toggle_node": ["j"] }
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Read(strings.NewReader(`{"continuous": {"fly": ["yaw_left", "yaw_right"]}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if !b.Continuous("fly", ActYawLeft) || b.Continuous("fly", ActThrustForward) || b.Continuous("translate", ActYawLeft) {
+		t.Errorf("continuous yaw only, got yaw %v thrust %v", b.Continuous("fly", ActYawLeft), b.Continuous("fly", ActThrustForward))
+	}
+
+	if a, _ := b.Lookup("fly", KeyEvent{Key: KeyI}); a != ActThrustForward {
+		t.Errorf("i got %q, want thrust_forward", a)
+	}
+	if a, _ := b.Lookup("fly", KeyEvent{Key: KeyKP0, Mods: ModControl}); a != ActThrustForward {
+		t.Errorf("ctrl+kp0 got %q, want thrust_forward", a)
+	}
+	if a, ok := b.Lookup("fly", KeyEvent{Key: KeyZ}); ok {
+		t.Errorf("z is no longer bound, got %q", a)
+	}
+	if a, _ := b.Lookup("translate", KeyEvent{Key: KeyJ}); a != ActToggleNode {
+		t.Errorf("j got %q, want toggle_node", a)
+	}
+	//the rest keeps its binding
+	if a, _ := b.Lookup("fly", KeyEvent{Key: KeyZ, Mods: ModControl}); a != ActThrustBackward {
+		t.Errorf("ctrl+z got %q, want thrust_backward", a)
+	}
+
+	for _, bad := range []string{
+		`{"fly": {"thrust_forward": ["z"]`,
+		`{"flying": {"thrust_forward": ["z"]}}`,
+		`{"fly": {"warp": ["z"]}}`,
+		`{"fly": {"thrust_forward": ["ctrl+"]}}`,
+		`{"fly": {"thrust_forward": ["p"]}}`,
+		`{"continuous": {"fly": ["spin"]}}`,
+		`{"continuous": {"global": ["stop"]}}`,
+	} {
+		if err := DefaultBindings().Read(strings.NewReader(bad)); err == nil {
+			t.Errorf("keymap %s: no error", bad)
+		}
+	}
+}
+
+//what Write saves Read must load back unchanged
+func TestWriteBindings(t *testing.T) {
+
+	want := DefaultBindings()
+	want.AnalogFly()
+	var buf bytes.Buffer
+	if err := want.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &Bindings{
+		sections:   make(map[string]map[Action][]KeyEvent),
+		continuous: make(map[string]map[Action]bool),
+	}
+	for section := range defaultKeys {
+		b.sections[section] = make(map[Action][]KeyEvent)
+		for a := range defaultKeys[section] {
+			b.sections[section][a] = nil
+		}
+	}
+	if err := b.Read(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for section, actions := range want.sections {
+		for a, kevs := range actions {
+			if b.Continuous(section, a) != want.Continuous(section, a) {
+				t.Errorf("%s %s: continuous %v, want %v", section, a, b.Continuous(section, a), want.Continuous(section, a))
+			}
+			got := b.Keys(section, a)
+			if len(got) != len(kevs) {
+				t.Errorf("%s %s: got %v, want %v", section, a, got, kevs)
+				continue
+			}
+			for i := range kevs {
+				if got[i] != kevs[i] {
+					t.Errorf("%s %s: got %v, want %v", section, a, got, kevs)
+				}
+			}
+		}
+	}
+}
+
+//an unknown mode has a name that no keymap section has
+func TestUnknownModeName(t *testing.T) {
+
+	for _, mode := range []int{-1, len(modeNames), 99} {
+		if name := ModeName(mode); name != fmt.Sprintf("mode(%d)", mode) {
+			t.Errorf("ModeName(%d) = %q", mode, name)
+		}
+	}
+}
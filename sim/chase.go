@@ -0,0 +1,108 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/math32"
+)
+
+//Chase is a third person camera on a spring arm: it follows a target at an
+//offset in the target's own frame, lagging behind it and catching up like a
+//critically damped spring, and looks a little ahead of where the target is
+//going. When something else moves the camera, the orbit control say, the
+//arm takes the new place as its offset, so the camera can be orbited around
+//the moving target. The camera must be a child of the scene.
+type Chase struct {
+	Camera Mover
+	//Target is what the camera follows, see Demo.Update for switching it
+	Target Mover
+	//Offset is where the camera sits from the target, along the target's
+	//right, up and forward, e.g. (0, 1.5, -5) is above and behind it
+	Offset math32.Vector3
+	//PositionLag and RotationLag are the spring's smooth times, about how
+	//many seconds the camera takes to catch up, 0 is rigid
+	PositionLag, RotationLag float32
+	//LookAhead is how many seconds ahead along the target's velocity the
+	//camera looks, 0 looks at the target itself
+	LookAhead float32
+
+	//where the camera was put last, and the springs' state
+	started          bool
+	placed           math32.Vector3
+	posVelocity      math32.Vector3
+	rotVelocity      float32
+	lastPos, targVel math32.Vector3
+}
+
+//Reset forgets the springs and the target's velocity, the next Update
+//starts over from wherever the camera and the target are then
+func (ch *Chase) Reset() {
+	ch.started = false
+}
+
+//Update moves the camera one tick along after the target, it never finishes
+func (ch *Chase) Update(dtime float32) bool {
+
+	cam := ch.Camera.Node()
+	target := ch.Target.Node()
+
+	var targetPos math32.Vector3
+	target.WorldPosition(&targetPos)
+	var q math32.Quaternion
+	target.WorldQuaternion(&q)
+	basis := ch.Target.Axes().Basis(&q)
+	pos := cam.Position()
+
+	if !ch.started {
+		ch.started = true
+		ch.placed = pos
+		ch.lastPos = targetPos
+		ch.posVelocity.Zero()
+		ch.rotVelocity = 0
+	}
+
+	//moved by someone else, the arm takes it from there
+	if !pos.Equals(&ch.placed) {
+		ch.adopt(&pos, &targetPos, &basis)
+	}
+
+	//the target's velocity from how far it went, whatever moves it
+	ch.targVel.SubVectors(&targetPos, &ch.lastPos)
+	if dtime > 0 {
+		ch.targVel.DivideScalar(dtime)
+	}
+	ch.lastPos = targetPos
+
+	want := ch.arm(&targetPos, &basis)
+	pos = motion.SmoothDampVec3(&want, &pos, &ch.posVelocity, ch.PositionLag, 0, dtime)
+	cam.SetPositionVec(&pos)
+	ch.placed = pos
+
+	look := ch.targVel
+	look.MultiplyScalar(ch.LookAhead).Add(&targetPos)
+	wantQuat := LookAt(ch.Camera, &look)
+	cur := cam.Quaternion()
+	q = motion.SmoothDampQuat(&wantQuat, &cur, &ch.rotVelocity, ch.RotationLag, dtime)
+	cam.SetQuaternionQuat(&q)
+	return false
+}
+
+//arm returns where the camera belongs, Offset along the target's axes
+func (ch *Chase) arm(targetPos *math32.Vector3, basis *motion.Basis) math32.Vector3 {
+
+	var right, up, fwd math32.Vector3
+	right.Copy(&basis.Right).MultiplyScalar(ch.Offset.X)
+	up.Copy(&basis.Up).MultiplyScalar(ch.Offset.Y)
+	fwd.Copy(&basis.Forward).MultiplyScalar(ch.Offset.Z)
+	pos := *targetPos
+	pos.Add(&right).Add(&up).Add(&fwd)
+	return pos
+}
+
+//take the camera's place around the target as the new Offset
+func (ch *Chase) adopt(pos, targetPos *math32.Vector3, basis *motion.Basis) {
+
+	var d math32.Vector3
+	d.SubVectors(pos, targetPos)
+	ch.Offset.Set(d.Dot(&basis.Right), d.Dot(&basis.Up), d.Dot(&basis.Forward))
+	ch.posVelocity.Zero()
+}
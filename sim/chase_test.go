@@ -0,0 +1,140 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//a chase camera and a gopher for it to follow, the camera starts at the origin
+func newChase() (*Chase, *core.Node) {
+
+	scene := core.NewNode()
+	cam, target := core.NewNode(), core.NewNode()
+	scene.Add(cam)
+	scene.Add(target)
+	target.SetPosition(0, 0, 10)
+	ch := &Chase{Camera: CameraMover(cam), Target: ObjectMover(target), Offset: chaseOffset,
+		PositionLag: chasePositionLag, RotationLag: chaseRotationLag, LookAhead: chaseLookAhead}
+	return ch, target
+}
+
+//the camera lags behind a jump of the target and settles at the offset, behind
+//it and looking at it, without moving in one go
+func TestChaseSettlesAtOffset(t *testing.T) {
+
+	ch, target := newChase()
+	cam := ch.Camera.Node()
+	const dtime = 1.0 / 60
+
+	ch.Update(dtime)
+	if p := cam.Position(); p.Length() > 1 {
+		t.Errorf("the first tick jumped the camera to %v", p)
+	}
+	for i := 0; i < 180; i++ {
+		ch.Update(dtime)
+	}
+
+	want := target.Position()
+	want.Add(&chaseOffset)
+	if p := cam.Position(); p.DistanceTo(&want) > 0.01 {
+		t.Errorf("camera at %v, want %v", p, want)
+	}
+	toTarget := target.Position()
+	toTarget.Sub(&want).Normalize()
+	var q math32.Quaternion
+	cam.WorldQuaternion(&q)
+	if b := ch.Camera.Axes().Basis(&q); b.Forward.Dot(&toTarget) < 0.999 {
+		t.Errorf("camera looks along %v, want %v", b.Forward, toTarget)
+	}
+}
+
+//a target flying along keeps the camera trailing it and looking ahead of it
+func TestChaseLooksAhead(t *testing.T) {
+
+	ch, target := newChase()
+	cam := ch.Camera.Node()
+	const dtime, speed = 1.0 / 60, 4
+	//the target faces +Z, it flies sideways to +X so behind is not along the way
+	for i := 0; i < 300; i++ {
+		p := target.Position()
+		target.SetPosition(p.X+speed*dtime, p.Y, p.Z)
+		ch.Update(dtime)
+	}
+
+	camPos := cam.Position()
+	targetPos := target.Position()
+	if lag := targetPos.X - camPos.X; lag < 0.5 || lag > 2*speed*ch.PositionLag {
+		t.Errorf("camera %v trails the target %v by %v", camPos, targetPos, lag)
+	}
+
+	look := targetPos
+	look.X += speed * ch.LookAhead
+	look.Sub(&camPos).Normalize()
+	var q math32.Quaternion
+	cam.WorldQuaternion(&q)
+	if b := ch.Camera.Axes().Basis(&q); b.Forward.Dot(&look) < 0.999 {
+		t.Errorf("camera looks along %v, want ahead of the target along %v", b.Forward, look)
+	}
+}
+
+//the orbit control moving the camera moves the arm with it
+func TestChaseKeepsOrbitedPlace(t *testing.T) {
+
+	ch, target := newChase()
+	cam := ch.Camera.Node()
+	for i := 0; i < 120; i++ {
+		ch.Update(1.0 / 60)
+	}
+
+	//orbited round to the target's right
+	orbited := target.Position()
+	orbited.Add(math32.NewVector3(-5, 1.5, 0))
+	cam.SetPositionVec(&orbited)
+	for i := 0; i < 120; i++ {
+		ch.Update(1.0 / 60)
+	}
+	if p := cam.Position(); p.DistanceTo(&orbited) > 1e-4 {
+		t.Errorf("camera went back from %v to %v", orbited, p)
+	}
+	if want := math32.NewVector3(5, 1.5, 0); !ch.Offset.AlmostEquals(want, 1e-4) {
+		t.Errorf("offset %v, want %v", ch.Offset, want)
+	}
+}
+
+//C turns the chase on and off, S and 0 leave it on, and steering the camera
+//itself leaves it alone
+func TestDemoChase(t *testing.T) {
+
+	d := NewHeadless()
+	l := NewLoop(d, DefaultRate)
+	keys, _ := ParseKeys("c,m,z,z,s,0")
+	for _, kev := range keys {
+		l.OnKeyDown(kev)
+		l.RunTicks(30)
+	}
+	if !d.Chasing() {
+		t.Fatalf("S or 0 stopped the chase")
+	}
+	l.RunTicks(120)
+	want := d.Gopher.Position()
+	want.Add(&chaseOffset)
+	if p := d.Camera.Position(); p.DistanceTo(&want) > 0.05 {
+		t.Errorf("camera at %v, want behind the gopher at %v", p, want)
+	}
+
+	l.OnKeyDown(KeyEvent{Key: KeyN})
+	l.RunTicks(int(d.Switch.Duration*DefaultRate) + 1)
+	before := d.Camera.Position()
+	l.RunTicks(30)
+	if p := d.Camera.Position(); !p.Equals(&before) {
+		t.Errorf("camera moved from %v to %v chasing itself", before, p)
+	}
+
+	l.OnKeyDown(KeyEvent{Key: KeyC})
+	l.RunTicks(1)
+	if d.Chasing() {
+		t.Errorf("C did not stop the chase")
+	}
+}
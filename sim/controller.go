@@ -0,0 +1,508 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//Controller moves one node, it owns its movement mode, the Mover it steers and
+//all of its scratch vectors, so any number of them can run in one scene
+type Controller struct {
+	//the node being moved, and which way it faces
+	mover Mover
+
+	//controls the current movement mode, simple translation, flying, on foot, walking, in space, in the air, on wheels or hovering
+	mvType int
+
+	//supporting actors
+	vecVelocity, vecVelocityPaused                  math32.Vector3
+	vecMovement, vecMovementGoal, vecMovementPaused math32.Vector3
+	vecRotation, vecRotationGoal, vecRotationPaused math32.Vector3
+
+	//how fly mode eases the rotation and the movement toward their goals
+	smoothing             Smoothing
+	smoothRot, smoothMove motion.Smoother
+
+	//these are the vectors that makes flying / looking where you're running possible
+	quatView, quatRot math32.Quaternion
+	viewBasis         motion.Basis
+
+	//the keys OnKeyDown understands, and those held for continuous actions
+	bindings *Bindings
+	input    Input
+	//the stick deflection of the held keys, and which axes are continuous
+	moveInput, rotInput math32.Vector3
+	moveOn, rotOn       [3]bool
+
+	//platform mode: how it jumps and falls, where it is in a jump, and a jump
+	//pressed but not yet done, jumpAsk seconds ago
+	jumping           Jumping
+	onGround, canJump bool
+	offGround         float32
+	jumpAsked         bool
+	jumpAsk           float32
+	//walk mode: how it walks and looks, where it looks, once looking is set
+	//from the mover, and how far into a step the head bob is
+	walking            Walking
+	looking            bool
+	lookYaw, lookPitch float32
+	bobPhase           float32
+	//spaceship mode: its mass and thrusters, and whether the flight assist is on
+	thrusters Thrusters
+	assist    bool
+	//aircraft mode: how it flies, its throttle, and whether its wing is stalled
+	airframe Airframe
+	throttle float32
+	stalled  bool
+	//vehicle mode: how it drives, where the wheel is and whether the handbrake is on
+	handling  Handling
+	steer     float32
+	handbrake bool
+	//drone mode: how it hovers, and the altitude it holds once holding
+	hover   Hover
+	holding bool
+	holdAlt float32
+	//the modes freezes is true for pause by stopping their clock, see TogglePause
+	frozen bool
+
+	//save some garbage collection
+	usePos, vecStep math32.Vector3
+}
+
+//NewController returns a controller in translate mode steering m
+func NewController(m Mover) *Controller {
+	c := &Controller{mvType: Translate, bindings: DefaultBindings()}
+	c.jumping = Jumping{Gravity: gravity, JumpSpeed: jumpSpeed, CoyoteTime: coyoteTime, JumpBuffer: jumpBuffer}
+	c.walking = Walking{Speed: walkSpeed, EyeHeight: eyeHeight, Sensitivity: lookSensitivity,
+		PitchLimit: lookPitchLimit, Bob: headBob, Stride: stride}
+	c.thrusters = Thrusters{Mass: shipMass, Force: shipForce, Inertia: shipInertia, Torque: shipTorque}
+	c.airframe = Airframe{Thrust: airThrust, Lift: airLift, Drag: airDrag, InducedDrag: airInducedDrag,
+		SideForce: airSideForce, StallAngle: stallAngle, StallSpeed: stallSpeed, StallPitch: stallDrop,
+		PitchRate: pitchRate, YawRate: yawRate, RollRate: rollRate, Gravity: gravity}
+	c.handling = Handling{Wheelbase: wheelbase, MaxSteer: maxSteer, SteerRate: steerRate,
+		Engine: engine, Brake: brake, Reverse: reverse, PedalRate: pedalRate, Drag: carDrag,
+		Grip: grip, HandbrakeGrip: handbrakeGrip, HandbrakeBrake: handbrakeOn}
+	c.hover = Hover{MaxTilt: maxTilt, LevelGain: levelGain, YawRate: droneYawRate, ClimbRate: climbRate,
+		MaxThrust: maxThrust, AltitudeGain: altitudeGain, Drag: droneDrag, Gravity: gravity}
+	c.SetMover(m)
+	c.SetSmoothing(SmoothApproach)
+	return c
+}
+
+//SetSmoothing chooses how fly mode eases toward the thrust and turn goals
+func (c *Controller) SetSmoothing(s Smoothing) {
+	c.smoothing = s
+	c.smoothRot = flyRotSmoothing.smoother(s)
+	c.smoothMove = flyMoveSmoothing.smoother(s)
+}
+
+//Smoothing returns how fly mode eases toward its goals
+func (c *Controller) Smoothing() Smoothing {
+	return c.smoothing
+}
+
+//Node returns the node being moved
+func (c *Controller) Node() *core.Node {
+	return c.mover.Node()
+}
+
+//Mover returns what is being steered
+func (c *Controller) Mover() Mover {
+	return c.mover
+}
+
+//SetMover hands the controller another Mover, motion carries over as is
+//since thrust and turns are along each mover's own axes
+func (c *Controller) SetMover(m Mover) {
+	c.mover = m
+	c.looking = false
+	c.holding = false
+}
+
+//Basis returns the mover's current forward, right and up in world space
+func (c *Controller) Basis() motion.Basis {
+	var q math32.Quaternion
+	c.Node().WorldQuaternion(&q)
+	return c.mover.Axes().Basis(&q)
+}
+
+//Mode returns the current movement mode, Translate, Fly, Platform, Walk, Spaceship, Aircraft, Vehicle or Drone
+func (c *Controller) Mode() int {
+	return c.mvType
+}
+
+//SetMode changes the movement mode, it does not stop any motion, an unknown
+//mode leaves it as it is
+func (c *Controller) SetMode(mode int) {
+	if !validMode(mode) {
+		return
+	}
+	c.mvType = mode
+	c.looking = false
+	c.holding = false
+}
+
+//Velocity returns the current velocity of the node
+func (c *Controller) Velocity() math32.Vector3 {
+	return c.vecVelocity
+}
+
+//the movement routine, called once per frame with the frame's delta time
+func (c *Controller) Update(dtime float32) {
+
+	node := c.mover.Node()
+
+	c.moveInput, c.rotInput, c.moveOn, c.rotOn = c.input.stick(c.bindings, ModeName(c.mvType))
+
+	switch c.mvType {
+
+	case Translate:
+		//continuous axes follow the held keys, speeding up and slowing down smoothly
+		for i := 0; i < 3; i++ {
+			if c.moveOn[i] {
+				c.vecVelocity.SetComponent(i, motion.Approach(c.moveInput.Component(i)*heldSpeedTranslate,
+					c.vecVelocity.Component(i), heldRampTranslate*dtime))
+			}
+			if c.rotOn[i] {
+				c.vecRotation.SetComponent(i, motion.Approach(c.rotInput.Component(i)*heldRotTranslate,
+					c.vecRotation.Component(i), heldRotRampTranslate*dtime))
+			}
+		}
+
+		//velocity is units per second and rotation radians per second, so scale
+		//them by the frame time, same motion at 30 or at 144 frames per second
+		c.vecStep = c.vecVelocity
+		c.vecStep.MultiplyScalar(dtime)
+		c.usePos = node.Position() //sadly can't work with Position() directly...
+		node.SetPositionVec(c.usePos.Add(&c.vecStep))
+		node.RotateX(c.vecRotation.X * dtime)
+		node.RotateY(c.vecRotation.Y * dtime)
+		node.RotateZ(c.vecRotation.Z * dtime)
+
+	case Fly:
+
+		//analog style, held keys ramp the goals up and released they ease back off,
+		//approach() below smooths the result as it does for the key presses
+		for i := 0; i < 3; i++ {
+			if c.moveOn[i] {
+				c.vecMovementGoal.SetComponent(i, motion.Approach(c.moveInput.Component(i)*heldThrustFly,
+					c.vecMovementGoal.Component(i), heldThrustRampFly*dtime))
+			}
+			if c.rotOn[i] {
+				c.vecRotationGoal.SetComponent(i, motion.Approach(c.rotInput.Component(i)*heldRotFly,
+					c.vecRotationGoal.Component(i), heldRotRampFly*dtime))
+			}
+		}
+
+		//the smoother applies smooth motions, approach() by default, X is pitch, Y yaw
+		//and Z roll about the mover's own axes
+		c.vecRotation = c.smoothRot.Smooth(&c.vecRotationGoal, &c.vecRotation, dtime)
+		c.quatRot = node.Quaternion()
+		c.mover.Axes().Rotate(&c.quatRot, c.vecRotation.X, c.vecRotation.Y, c.vecRotation.Z)
+		node.SetQuaternionQuat(&c.quatRot)
+
+		c.vecMovement = c.smoothMove.Smooth(&c.vecMovementGoal, &c.vecMovement, dtime)
+
+		//we need the forward direction and the two axes at 90 deg from it so we can apply trhust,
+		//taken from the world quaternion they are always the node's true local axes
+		node.WorldQuaternion(&c.quatView)
+		c.viewBasis = c.mover.Axes().Basis(&c.quatView)
+
+		//build velocity vector from the buffered (approach'd) movement along those axes
+		c.vecVelocity = c.viewBasis.Velocity(&c.vecMovement)
+
+		//finally apply the manipulated velocity to the position, et voila: motion
+		c.usePos = node.Position()
+		node.SetPositionVec(c.usePos.Add(&c.vecVelocity))
+
+		//gravity (notice it is placed on movement not velocity, it will be applied next frame):
+		//symbolically c.vecMovement = c.vecMovement + c.vecGravity * dtime;
+		//g3n'd c.vecMovement.Add(c.vecGravity.MultiplyScalar(dtime))
+		//since I didn't have a run and jump style demo I did not implement a gravity vector
+		//above is how you would do it with a vecGravity like (0, -9.8, 0) where the -9.8
+		//is earth's gravity attractive acceleration which will generally be in the Y axis but may be your Z
+		//see https://www.youtube.com/watch?v=c4b9lCfSDQM&list=PLW3Zl3wyJwWOpdhYedlD-yCB7WQoHf-My&index=12
+		//platform mode is the run and jump demo, see updatePlatform
+
+	case Platform:
+		c.updatePlatform(dtime)
+
+	case Walk:
+		c.updateWalk(dtime)
+
+	case Spaceship:
+		c.updateSpaceship(dtime)
+
+	case Aircraft:
+		c.updateAircraft(dtime)
+
+	case Vehicle:
+		c.updateVehicle(dtime)
+
+	case Drone:
+		c.updateDrone(dtime)
+	}
+}
+
+//SetBindings changes the keys OnKeyDown understands, see DefaultBindings
+func (c *Controller) SetBindings(b *Bindings) {
+	c.bindings = b
+}
+
+//key handler for the movement keys of the current mode, false if the key is
+//not bound to a movement action of the mode. A continuous action is held
+//until OnKeyUp of the same key.
+func (c *Controller) OnKeyDown(kev KeyEvent) bool {
+
+	section := ModeName(c.mvType)
+	a, ok := c.bindings.Lookup(section, kev)
+	if !ok {
+		return false
+	}
+	if c.bindings.Continuous(section, a) {
+		c.input.Press(kev.Key, a)
+		return true
+	}
+	return c.OnAction(a)
+}
+
+//key up handler, lets go of the continuous action the key was held for
+func (c *Controller) OnKeyUp(kev KeyEvent) {
+	c.input.Release(kev.Key)
+}
+
+//OnAction does a movement action of the current mode, false if the mode has
+//no such action
+func (c *Controller) OnAction(a Action) bool {
+
+	switch c.mvType {
+
+	case Translate:
+		return c.Translate(a)
+
+	case Fly:
+		return c.Fly(a)
+
+	case Platform:
+		return c.Platform(a)
+
+	case Walk:
+		return c.Walk(a)
+
+	case Spaceship:
+		return c.Spaceship(a)
+
+	case Aircraft:
+		return c.Aircraft(a)
+
+	case Vehicle:
+		return c.Vehicle(a)
+
+	case Drone:
+		return c.Drone(a)
+	}
+	return false
+}
+
+//this sets the motion vectors that will be used in simple translation, called from OnAction
+func (c *Controller) Translate(a Action) bool {
+
+	switch a {
+	case ActAccelerate:
+		c.vecVelocity.MultiplyScalar(incAcceleration)
+
+	case ActDecelerate:
+		c.vecVelocity.MultiplyScalar(1 / incAcceleration)
+
+		//these are local xyz/s, they move along the world axes. Rotations do not affect the linear
+		//movement so good for modeling/moving satellites, thrown bottles, etc.
+	case ActMoveXPlus:
+		c.vecVelocity.X += incrementLinearTranslate
+
+	case ActMoveXMinus:
+		c.vecVelocity.X -= incrementLinearTranslate
+
+	case ActMoveYPlus:
+		c.vecVelocity.Y += incrementLinearTranslate
+
+	case ActMoveYMinus:
+		c.vecVelocity.Y -= incrementLinearTranslate
+
+	case ActMoveZPlus:
+		c.vecVelocity.Z += incrementLinearTranslate
+
+	case ActMoveZMinus:
+		c.vecVelocity.Z -= incrementLinearTranslate
+
+		//the rotations, radians/s about the node's own axes
+	case ActRotateXPlus:
+		c.vecRotation.X += incrementRotTranslate
+
+	case ActRotateXMinus:
+		c.vecRotation.X -= incrementRotTranslate
+
+	case ActRotateYPlus:
+		c.vecRotation.Y += incrementRotTranslate
+
+	case ActRotateYMinus:
+		c.vecRotation.Y -= incrementRotTranslate
+
+	case ActRotateZPlus:
+		c.vecRotation.Z += incrementRotTranslate
+
+	case ActRotateZMinus:
+		c.vecRotation.Z -= incrementRotTranslate
+
+	default:
+		return false
+	}
+	return true
+}
+
+//this sets the motion vectors that will be used in flying using approach methods, called from OnAction
+func (c *Controller) Fly(a Action) bool {
+
+	switch a {
+
+	//This applies a LARGE change so that the approach() can be easily seen
+	case ActSpin:
+		c.vecRotationGoal.X += math32.Pi / -8
+
+	case ActSpinReverse:
+		c.vecRotationGoal.X += math32.Pi / 8
+
+	case ActAccelerate:
+		c.vecVelocity.MultiplyScalar(incAcceleration)
+
+	case ActDecelerate:
+		c.vecVelocity.MultiplyScalar(1 / incAcceleration)
+
+	//turns are about the mover's own axes, see motion.Axes.Rotate
+	case ActPitchUp:
+		c.vecRotationGoal.X += incrementRotFly
+
+	case ActPitchDown:
+		c.vecRotationGoal.X -= incrementRotFly
+
+	case ActYawLeft:
+		c.vecRotationGoal.Y += incrementRotFly
+
+	case ActYawRight:
+		c.vecRotationGoal.Y -= incrementRotFly
+
+	case ActRollLeft: //counterclockwise
+		c.vecRotationGoal.Z += incrementRotFly
+
+	case ActRollRight:
+		c.vecRotationGoal.Z -= incrementRotFly
+
+	//thrust is along the mover's own axes, see motion.Axes.Basis
+	case ActThrustForward:
+		c.vecMovementGoal.Z += incrementLinear
+
+	case ActThrustBackward:
+		c.vecMovementGoal.Z -= incrementLinear
+
+	case ActThrustLeft:
+		c.vecMovementGoal.X -= incrementLinear
+
+	case ActThrustRight:
+		c.vecMovementGoal.X += incrementLinear
+
+	case ActThrustUp:
+		c.vecMovementGoal.Y += incrementLinear
+
+	case ActThrustDown:
+		c.vecMovementGoal.Y -= incrementLinear
+
+	default:
+		return false
+	}
+	return true
+}
+
+//stop all rotations
+func (c *Controller) StopRotation() {
+	c.vecRotation.Zero()
+	c.vecRotationPaused.Zero()
+	c.vecRotationGoal.Zero()
+	c.smoothRot.Reset()
+}
+
+//freezes is true for the modes that pause by stopping their clock
+func freezes(mode int) bool {
+	switch mode {
+	case Platform, Walk, Spaceship, Aircraft, Vehicle, Drone:
+		return true
+	}
+	return false
+}
+
+//Pause movement of the node
+func (c *Controller) TogglePause() {
+	//gravity would get a paused jump or flight falling again, platform, walk,
+	//vehicle and drone mode work out their velocity every tick and a
+	//spaceship's or an aircraft's velocity is all it has, they stop their
+	//clock instead
+	if freezes(c.mvType) {
+		c.frozen = !c.frozen
+		return
+	}
+
+	if c.vecVelocity.Equals(&zeroVector) {
+		c.vecVelocity.Copy(&c.vecVelocityPaused)
+	} else {
+		c.vecVelocityPaused.Copy(&c.vecVelocity)
+		c.vecVelocity.Zero()
+	}
+
+	switch c.mvType {
+	case Translate:
+
+		if c.vecRotation.Equals(&zeroVector) {
+			c.vecRotation.Copy(&c.vecRotationPaused)
+		} else {
+			c.vecRotationPaused.Copy(&c.vecRotation)
+			c.vecRotation.Zero()
+		}
+
+	case Fly:
+		if c.vecMovementGoal.Equals(&zeroVector) {
+			c.vecMovementGoal.Copy(&c.vecMovementPaused)
+		} else {
+			c.vecMovementPaused.Copy(&c.vecMovementGoal)
+			c.vecMovementGoal.Zero()
+		}
+
+		if c.vecRotationGoal.Equals(&zeroVector) {
+			c.vecRotationGoal.Copy(&c.vecRotationPaused)
+		} else {
+			c.vecRotationPaused.Copy(&c.vecRotationGoal)
+			c.vecRotationGoal.Zero()
+		}
+	}
+
+}
+
+//Stop all movements of the node, once stopped you can continue on by
+//pressing the movement keys again
+func (c *Controller) Stop() {
+	c.StopRotation()
+
+	c.vecVelocity.Zero()
+	c.vecVelocityPaused.Zero()
+
+	c.vecMovement.Zero()
+	c.vecMovementGoal.Zero()
+	c.vecMovementPaused.Zero()
+	c.smoothMove.Reset()
+
+	c.jumpAsked = false
+	c.throttle = 0
+	c.steer, c.handbrake = 0, false
+	c.holding = false
+	c.frozen = false
+	c.looking = false
+	c.bobPhase = 0
+}
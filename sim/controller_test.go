@@ -0,0 +1,247 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//two controllers in one scene must not share any state
+func TestControllersAreIndependent(t *testing.T) {
+
+	translator := NewController(ObjectMover(core.NewNode()))
+	flyer := NewController(ObjectMover(core.NewNode()))
+	flyer.SetMode(Fly)
+
+	translator.OnKeyDown(KeyEvent{Key: KeyX})
+	translator.OnKeyDown(KeyEvent{Key: KeyY, Mods: ModShift})
+	flyer.OnKeyDown(KeyEvent{Key: KeyZ})
+
+	for i := 0; i < 60; i++ {
+		translator.Update(1.0 / 60)
+		flyer.Update(1.0 / 60)
+	}
+
+	tp := translator.Node().Position()
+	if !near(tp.X, incrementLinearTranslate) || tp.Y != 0 || tp.Z != 0 {
+		t.Errorf("translator position %v, want (%v, 0, 0)", tp, incrementLinearTranslate)
+	}
+	if tr := translator.Node().Rotation(); tr.Y == 0 {
+		t.Errorf("translator did not rotate about Y, rotation %v", tr)
+	}
+
+	fp := flyer.Node().Position()
+	if fp.X != 0 || fp.Y != 0 || fp.Z <= 0 {
+		t.Errorf("flyer position %v, want it moved forward along +Z only", fp)
+	}
+	if fr := flyer.Node().Rotation(); fr.X != 0 || fr.Y != 0 || fr.Z != 0 {
+		t.Errorf("flyer picked up rotation %v", fr)
+	}
+
+	//stopping one leaves the other moving
+	translator.Stop()
+	v := flyer.Velocity()
+	if v.Length() == 0 {
+		t.Errorf("stopping the translator stopped the flyer")
+	}
+}
+
+//thrusting H, V and Z must move the node along its own left, up and forward,
+//also after a LookAt and a roll, for the gopher and for a camera
+func TestFlyThrustAlongLocalAxes(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		isGopher bool
+		key      Key
+		//the wanted direction in the node's local frame
+		x, y, z float32
+	}{
+		{"gopher forward", true, KeyZ, 0, 0, 1},
+		{"gopher left", true, KeyH, 1, 0, 0},
+		{"gopher up", true, KeyV, 0, 1, 0},
+		{"camera forward", false, KeyZ, 0, 0, -1},
+		{"camera left", false, KeyH, -1, 0, 0},
+		{"camera up", false, KeyV, 0, 1, 0},
+	}
+
+	for _, tt := range tests {
+		node := core.NewNode()
+		node.SetPosition(3, 1, -2)
+		node.LookAt(math32.NewVector3(-5, 6, 4), math32.NewVector3(0, 1, 0))
+		node.RotateZ(0.9)
+		node.RotateX(-0.4)
+
+		m := ObjectMover(node)
+		if !tt.isGopher {
+			m = CameraMover(node)
+		}
+		c := NewController(m)
+		c.SetMode(Fly)
+		c.OnKeyDown(KeyEvent{Key: tt.key})
+
+		var q math32.Quaternion
+		node.WorldQuaternion(&q)
+		want := math32.NewVector3(tt.x, tt.y, tt.z).ApplyQuaternion(&q)
+
+		start := node.Position()
+		for i := 0; i < 30; i++ {
+			c.Update(1.0 / 60)
+		}
+		moved := node.Position()
+		moved.Sub(&start).Normalize()
+
+		if !moved.AlmostEquals(want, 1e-3) {
+			t.Errorf("%s: moved along %v, want %v", tt.name, moved, want)
+		}
+	}
+}
+
+//P, Y and R must pitch the nose up, yaw it left and roll the left wing down,
+//the same for a mesh and a camera
+func TestFlyTurnsAlongLocalAxes(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		key   Key
+		check func(before, after motion.Basis) bool
+	}{
+		{"pitch lifts the nose", KeyP, func(before, after motion.Basis) bool {
+			return after.Forward.Dot(&before.Up) > 0.01
+		}},
+		{"yaw turns left", KeyY, func(before, after motion.Basis) bool {
+			return after.Forward.Dot(&before.Right) < -0.01
+		}},
+		{"roll drops the left wing", KeyR, func(before, after motion.Basis) bool {
+			return after.Up.Dot(&before.Right) < -0.01
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, m := range []Mover{ObjectMover(core.NewNode()), CameraMover(core.NewNode())} {
+			m.Node().LookAt(math32.NewVector3(4, 2, -7), math32.NewVector3(0, 1, 0))
+			c := NewController(m)
+			c.SetMode(Fly)
+			before := c.Basis()
+
+			c.OnKeyDown(KeyEvent{Key: tt.key})
+			for i := 0; i < 30; i++ {
+				c.Update(1.0 / 60)
+			}
+
+			if after := c.Basis(); !tt.check(before, after) {
+				t.Errorf("%s: failed for axes %v, basis before %v after %v", tt.name, *m.Axes(), before, after)
+			}
+		}
+	}
+}
+
+//a held continuous key pushes its axis only while it is held, an impulse
+//key keeps its effect after it comes up
+func TestHeldKeys(t *testing.T) {
+
+	b := DefaultBindings()
+	b.AnalogFly()
+	if err := b.SetContinuous("translate", ActMoveXPlus, true); err != nil {
+		t.Fatal(err)
+	}
+
+	flyer := NewController(ObjectMover(core.NewNode()))
+	flyer.SetBindings(b)
+	flyer.SetMode(Fly)
+	translator := NewController(ObjectMover(core.NewNode()))
+	translator.SetBindings(b)
+
+	flyer.OnKeyDown(KeyEvent{Key: KeyZ})
+	translator.OnKeyDown(KeyEvent{Key: KeyX})
+	translator.OnKeyDown(KeyEvent{Key: KeyY})
+	for i := 0; i < 60; i++ {
+		flyer.Update(1.0 / 60)
+		translator.Update(1.0 / 60)
+	}
+
+	if v := flyer.Velocity(); v.Z <= 0 {
+		t.Errorf("held thrust did not move the flyer forward, velocity %v", v)
+	}
+	if v := translator.Velocity(); v.X <= incrementLinearTranslate || !near(v.Y, incrementLinearTranslate) {
+		t.Errorf("translator velocity %v, want X held up past one press and Y one press", v)
+	}
+
+	flyer.OnKeyUp(KeyEvent{Key: KeyZ})
+	translator.OnKeyUp(KeyEvent{Key: KeyX, Mods: ModShift})
+	translator.OnKeyUp(KeyEvent{Key: KeyY})
+	for i := 0; i < 600; i++ {
+		flyer.Update(1.0 / 60)
+		translator.Update(1.0 / 60)
+	}
+
+	if v := flyer.Velocity(); v.Length() != 0 {
+		t.Errorf("released thrust still moves the flyer, velocity %v", v)
+	}
+	if v := translator.Velocity(); v.X != 0 || !near(v.Y, incrementLinearTranslate) {
+		t.Errorf("translator velocity %v, want X back to 0 and Y still one press", v)
+	}
+}
+
+//every smoothing flies forward when thrusting and comes to rest on a stop
+//of thrust, as does the sphere1 demo
+func TestSmoothings(t *testing.T) {
+
+	for _, name := range []string{"approach", "damp", "decay"} {
+		s, err := ParseSmoothing(name)
+		if err != nil || s.String() != name {
+			t.Fatalf("ParseSmoothing(%q) = %v, %v", name, s, err)
+		}
+
+		d := NewHeadless()
+		d.SetSmoothing(s)
+		l := NewLoop(d, DefaultRate)
+		keys, _ := ParseKeys("m,d,z,z,z")
+		for _, kev := range keys {
+			l.OnKeyDown(kev)
+		}
+		l.RunTicks(60)
+		if p := d.Gopher.Position(); p.Z <= 0 {
+			t.Errorf("%s: gopher at %v, want moved forward", name, p)
+		}
+		if v := d.vecAppVelocity; v.Z <= 0 {
+			t.Errorf("%s: sphere1 velocity %v, want moving", name, v)
+		}
+
+		l.OnKeyDown(KeyEvent{Key: KeyZ, Mods: ModControl})
+		l.OnKeyDown(KeyEvent{Key: KeyZ, Mods: ModControl})
+		l.OnKeyDown(KeyEvent{Key: KeyZ, Mods: ModControl})
+		l.OnKeyDown(KeyEvent{Key: KeyE})
+		l.OnKeyDown(KeyEvent{Key: KeyS})
+		l.RunTicks(180)
+		if v := d.Mover.Velocity(); v.Length() > 1e-4 {
+			t.Errorf("%s: velocity %v after a stop, want at rest", name, v)
+		}
+	}
+
+	if _, err := ParseSmoothing("springy"); err == nil {
+		t.Errorf("unknown smoothing parsed")
+	}
+	if name := Smoothing(len(smoothingNames)).String(); name != "smoothing(3)" {
+		t.Errorf("unknown smoothing named %q", name)
+	}
+}
+
+func near(a, b float32) bool {
+	d := a - b
+	return d < 1e-4 && d > -1e-4
+}
+
+//a mode out of range is refused and the controller stays in its mode
+func TestUnknownMode(t *testing.T) {
+
+	c := newInMode(Fly, ObjectMover(core.NewNode()))
+	for _, mode := range []int{-1, len(modeNames), 99} {
+		c.SetMode(mode)
+		if c.Mode() != Fly {
+			t.Errorf("SetMode(%d) changed the mode to %d", mode, c.Mode())
+		}
+	}
+}
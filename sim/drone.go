@@ -0,0 +1,187 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/math32"
+)
+
+//Hover tunes drone mode, see Controller.SetHover. The drone levels itself
+//and holds its altitude, the sticks only ask for a tilt, a climb or a turn.
+type Hover struct {
+	//MaxTilt is how far the drone tilts at full stick, in radians, and
+	//LevelGain how quickly it gets to the tilt asked for, per second: the
+	//turn rate goal is LevelGain times how far off it is
+	MaxTilt, LevelGain float32
+	//YawRate is how fast it turns at full stick, ClimbRate how fast it
+	//climbs or sinks, in radians and units per second
+	YawRate, ClimbRate float32
+	//MaxThrust is the most the rotors can lift, AltitudeGain how quickly
+	//it gets back to the held altitude, per second
+	MaxThrust, AltitudeGain float32
+	//Drag slows it by this much per unit of speed, tilted by t it flies at
+	//Gravity tan(t) / Drag
+	Drag float32
+	//Gravity pulls down along Y, in units per second squared
+	Gravity float32
+}
+
+//SetHover changes how drone mode hovers and flies
+func (c *Controller) SetHover(h Hover) {
+	c.hover = h
+}
+
+//Hover returns how drone mode hovers and flies
+func (c *Controller) Hover() Hover {
+	return c.hover
+}
+
+//this moves the sticks in drone mode, called from OnAction. Fly mode's
+//thrust asks to fly forward, sideways, up or down, its pitch and roll to
+//tilt, which comes to the same, and its yaw to turn. The keys are held by
+//default, pressed they trim the sticks.
+func (c *Controller) Drone(a Action) bool {
+
+	stick := func(v *math32.Vector3, i int, by float32) {
+		v.SetComponent(i, math32.Clamp(v.Component(i)+by, -1, 1))
+	}
+	switch a {
+
+	//X is right, Y up and Z forward like the fly thrust
+	case ActThrustForward:
+		stick(&c.vecMovementGoal, 2, droneStep)
+
+	case ActThrustBackward:
+		stick(&c.vecMovementGoal, 2, -droneStep)
+
+	case ActThrustLeft:
+		stick(&c.vecMovementGoal, 0, -droneStep)
+
+	case ActThrustRight:
+		stick(&c.vecMovementGoal, 0, droneStep)
+
+	case ActThrustUp:
+		stick(&c.vecMovementGoal, 1, droneStep)
+
+	case ActThrustDown:
+		stick(&c.vecMovementGoal, 1, -droneStep)
+
+	//nose up flies backward, rolled left it flies left
+	case ActPitchUp:
+		stick(&c.vecMovementGoal, 2, -droneStep)
+
+	case ActPitchDown:
+		stick(&c.vecMovementGoal, 2, droneStep)
+
+	case ActRollLeft:
+		stick(&c.vecMovementGoal, 0, -droneStep)
+
+	case ActRollRight:
+		stick(&c.vecMovementGoal, 0, droneStep)
+
+	//the yaw is a turn rate like fly mode's
+	case ActYawLeft:
+		c.vecRotationGoal.Y += incrementRotTranslate
+
+	case ActYawRight:
+		c.vecRotationGoal.Y -= incrementRotTranslate
+
+	default:
+		return false
+	}
+	return true
+}
+
+//drone mode, called from Update: the self levelling sets the pitch and roll
+//goals from the tilt the sticks ask for, the rotors hold the altitude
+//whatever the tilt, and the tilt flies it about. The velocity is in world
+//units per second.
+func (c *Controller) updateDrone(dtime float32) {
+
+	if c.frozen {
+		return
+	}
+	node := c.mover.Node()
+	hv := &c.hover
+
+	//the sticks, held keys or else the trim
+	var move math32.Vector3
+	for i := 0; i < 3; i++ {
+		move.SetComponent(i, c.vecMovementGoal.Component(i))
+		if c.moveOn[i] {
+			move.SetComponent(i, c.moveInput.Component(i))
+		}
+	}
+	if c.rotOn[0] {
+		move.Z -= c.rotInput.X
+	}
+	if c.rotOn[2] {
+		move.X -= c.rotInput.Z
+	}
+	if c.rotOn[1] {
+		c.vecRotationGoal.Y = c.rotInput.Y * hv.YawRate
+	}
+
+	//self levelling: the pitch and roll goals close the gap to the tilt asked
+	//for, no stick is level. Nose down flies forward, rolled right it flies right.
+	node.WorldQuaternion(&c.quatView)
+	c.viewBasis = c.mover.Axes().Basis(&c.quatView)
+	pitch, roll := c.tilt()
+	wantPitch := -math32.Clamp(move.Z, -1, 1) * hv.MaxTilt
+	wantRoll := -math32.Clamp(move.X, -1, 1) * hv.MaxTilt
+	c.vecRotationGoal.X = hv.LevelGain * (wantPitch - pitch)
+	c.vecRotationGoal.Z = hv.LevelGain * (wantRoll - roll)
+	for i := 0; i < 3; i++ {
+		c.vecRotation.SetComponent(i, motion.Approach(c.vecRotationGoal.Component(i), c.vecRotation.Component(i), droneRamp*dtime))
+	}
+
+	//pitch and roll about its own axes, the yaw about the world's up so a
+	//turn does not tip it
+	c.quatRot = node.Quaternion()
+	c.mover.Axes().Rotate(&c.quatRot, c.vecRotation.X*dtime, 0, c.vecRotation.Z*dtime)
+	node.SetQuaternionQuat(&c.quatRot)
+	c.turnWorld(&worldUp, c.vecRotation.Y*dtime)
+	node.WorldQuaternion(&c.quatView)
+	c.viewBasis = c.mover.Axes().Basis(&c.quatView)
+
+	//the altitude stays put unless V asks for a climb, the rotors give as
+	//much lift as that takes, along the tilted up. The speed loop is twice as
+	//quick as the altitude one, so it settles without bobbing.
+	c.usePos = node.Position()
+	climb := math32.Clamp(move.Y, -1, 1)
+	if climb != 0 || !c.holding {
+		c.holdAlt, c.holding = c.usePos.Y, true
+	}
+	wantVY := climb*hv.ClimbRate + hv.AltitudeGain*(c.holdAlt-c.usePos.Y)
+	lift := hv.Gravity + 2*hv.AltitudeGain*(wantVY-c.vecVelocity.Y)
+	if up := c.viewBasis.Up.Y; up > 0.1 {
+		lift /= up
+	}
+	lift = math32.Clamp(lift, 0, hv.MaxThrust)
+
+	var accel math32.Vector3
+	accel.Copy(&c.viewBasis.Up).MultiplyScalar(lift)
+	accel.Y -= hv.Gravity
+	drag := c.vecVelocity
+	accel.Sub(drag.MultiplyScalar(hv.Drag))
+	c.vecVelocity.Add(accel.MultiplyScalar(dtime))
+
+	//it lands on the floor and takes off from it
+	c.vecStep = c.vecVelocity
+	c.vecStep.MultiplyScalar(dtime)
+	was := c.usePos.Y
+	c.usePos.Add(&c.vecStep)
+	if h, ok := c.ground(c.usePos.X, c.usePos.Z); ok && c.usePos.Y <= h && was >= h {
+		c.usePos.Y = h
+		c.vecVelocity.Y = math32.Max(c.vecVelocity.Y, 0)
+		c.holdAlt = math32.Max(c.holdAlt, h)
+	}
+	node.SetPositionVec(&c.usePos)
+}
+
+//tilt returns how far the nose is up and how far it is rolled left, in radians
+func (c *Controller) tilt() (pitch, roll float32) {
+	b := &c.viewBasis
+	pitch = math32.Asin(math32.Clamp(b.Forward.Y, -1, 1))
+	roll = math32.Atan2(b.Right.Y, b.Up.Y)
+	return
+}
@@ -0,0 +1,82 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//let go it levels itself and holds the altitude, knocked about too
+func TestDroneHovers(t *testing.T) {
+
+	c := newInMode(Drone, ObjectMover(core.NewNode()))
+	c.Node().SetPosition(0, 5, 0)
+	c.ticks(1)
+	c.Node().RotateX(0.3)
+	c.Node().RotateZ(-0.2)
+	c.vecVelocity.Set(0, -2, 0)
+	c.ticks(300)
+
+	pitch, roll := c.tilt()
+	if math32.Abs(pitch) > 1e-3 || math32.Abs(roll) > 1e-3 {
+		t.Errorf("tilted %v, %v, want level", pitch, roll)
+	}
+	if y := c.Node().Position().Y; math32.Abs(y-5) > 0.02 {
+		t.Errorf("at %v, want back at 5", y)
+	}
+}
+
+//Z tilts the nose down and it flies forward at the same altitude, let go it
+//levels out again
+func TestDroneTiltsToTranslate(t *testing.T) {
+
+	c := newInMode(Drone, ObjectMover(core.NewNode()))
+	c.Node().SetPosition(0, 5, 0)
+	c.OnKeyDown(KeyEvent{Key: KeyZ})
+	c.ticks(180)
+	pitch, _ := c.tilt()
+	if hv := c.Hover(); math32.Abs(pitch+hv.MaxTilt) > 1e-3 {
+		t.Errorf("pitched %v, want the nose down by %v", pitch, hv.MaxTilt)
+	}
+	p := c.Node().Position()
+	if p.Z < 5 || math32.Abs(p.X) > 1e-3 || math32.Abs(p.Y-5) > 0.05 {
+		t.Errorf("flew to %v, want forward along +Z at 5", p)
+	}
+
+	c.OnKeyUp(KeyEvent{Key: KeyZ})
+	c.ticks(60)
+	if pitch, _ := c.tilt(); math32.Abs(pitch) > 1e-2 {
+		t.Errorf("still pitched %v a second after letting go", pitch)
+	}
+}
+
+//Y turns it where it hovers without changing the way it drifts, and V climbs
+func TestDroneYawAndClimb(t *testing.T) {
+
+	c := newInMode(Drone, ObjectMover(core.NewNode()))
+	c.Node().SetPosition(0, 5, 0)
+	c.vecVelocity.Set(0, 0, 3)
+	c.OnKeyDown(KeyEvent{Key: KeyY})
+	c.OnKeyDown(KeyEvent{Key: KeyV})
+	c.ticks(60)
+	c.OnKeyUp(KeyEvent{Key: KeyY})
+	c.OnKeyUp(KeyEvent{Key: KeyV})
+
+	if fwd := c.Basis().Forward; fwd.X < 0.5 {
+		t.Errorf("yawed left to %v, want well toward +X", fwd)
+	}
+	v := c.Velocity()
+	if math32.Abs(v.X) > 0.05 || v.Z < 1.5 {
+		t.Errorf("the turn changed the drift to %v", v)
+	}
+
+	climbed := c.Node().Position().Y
+	if climbed < 6.5 {
+		t.Errorf("climbed to %v, want about %v", climbed, 5+c.Hover().ClimbRate)
+	}
+	c.ticks(120)
+	if y := c.Node().Position().Y; math32.Abs(y-climbed) > 0.5 {
+		t.Errorf("went on from %v to %v, want it held", climbed, y)
+	}
+}
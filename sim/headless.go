@@ -0,0 +1,59 @@
+package sim
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//NodeState is a snapshot of a node's transform
+type NodeState struct {
+	Name               string
+	Position, Rotation math32.Vector3
+}
+
+func (ns NodeState) String() string {
+	return fmt.Sprintf("%-10s pos (%.4f, %.4f, %.4f) rot (%.4f, %.4f, %.4f)", ns.Name,
+		ns.Position.X, ns.Position.Y, ns.Position.Z,
+		ns.Rotation.X, ns.Rotation.Y, ns.Rotation.Z)
+}
+
+//States returns the transforms of all demo nodes
+func (d *Demo) States() []NodeState {
+
+	var states []NodeState
+	for _, n := range []*core.Node{d.Gopher, d.SoloGopher, d.Camera, d.Sphere1, d.Sphere2} {
+		states = append(states, NodeState{Name: n.Name(), Position: n.Position(), Rotation: n.Rotation()})
+	}
+	return states
+}
+
+//a headless demo with its keys bound by b, nil keeps the defaults
+func newHeadless(b *Bindings) *Demo {
+	d := NewHeadless()
+	if b != nil {
+		d.SetBindings(b)
+	}
+	return d
+}
+
+//RunHeadless is the library entry point: press the keys on a fresh headless
+//demo, run it for steps ticks at rate ticks a second and return the final
+//node states. The keys mean what b binds them to, nil is DefaultBindings.
+func RunHeadless(b *Bindings, keys []KeyEvent, steps int, rate float32) []NodeState {
+
+	return newHeadless(b).Run(keys, steps, rate)
+}
+
+//Run presses the keys, runs the demo for steps ticks at rate ticks a second
+//and returns the final node states, for a headless demo set up your own way
+func (d *Demo) Run(keys []KeyEvent, steps int, rate float32) []NodeState {
+
+	l := NewLoop(d, rate)
+	for _, kev := range keys {
+		l.OnKeyDown(kev)
+	}
+	l.RunTicks(steps)
+	return d.States()
+}
@@ -0,0 +1,41 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+//translate mode must end up in the same place after one simulated second,
+//whatever the frame rate
+func TestTranslateFrameRateIndependent(t *testing.T) {
+
+	keys, err := ParseKeys("x,x,ctrl+z,y,shift+y")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []NodeState
+	for _, fps := range []int{30, 60, 144} {
+		got := RunHeadless(nil, keys, fps, float32(fps))
+		if want == nil {
+			want = got
+			continue
+		}
+		for i := range got {
+			if !got[i].Position.AlmostEquals(&want[i].Position, 1e-4) ||
+				!got[i].Rotation.AlmostEquals(&want[i].Rotation, 1e-4) {
+				t.Errorf("%d fps: %v, want %v", fps, got[i], want[i])
+			}
+		}
+	}
+
+	//two X presses, one Ctrl-Z press and a Y press in one second
+	wantPos := math32.NewVector3(2*incrementLinearTranslate, incrementLinearTranslate, -incrementLinearTranslate)
+	if !want[0].Position.AlmostEquals(wantPos, 1e-4) {
+		t.Errorf("gopher at %v after one second, want %v", want[0].Position, wantPos)
+	}
+	if !near(want[0].Rotation.Y, incrementRotTranslate) {
+		t.Errorf("gopher yawed %v after one second, want %v", want[0].Rotation.Y, incrementRotTranslate)
+	}
+}
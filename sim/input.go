@@ -0,0 +1,173 @@
+package sim
+
+import "github.com/g3n/engine/math32"
+
+//axis is what a continuous action drives: one component of the movement or
+//the rotation input, and which way
+type axis struct {
+	rot  bool
+	comp int
+	sign float32
+}
+
+//axisActions are the actions that can be continuous, per mode. Held they
+//push their axis like a stick, released the axis goes back to rest.
+var axisActions = map[string]map[Action]axis{
+	"translate": {
+		ActMoveXPlus:    {false, 0, 1},
+		ActMoveXMinus:   {false, 0, -1},
+		ActMoveYPlus:    {false, 1, 1},
+		ActMoveYMinus:   {false, 1, -1},
+		ActMoveZPlus:    {false, 2, 1},
+		ActMoveZMinus:   {false, 2, -1},
+		ActRotateXPlus:  {true, 0, 1},
+		ActRotateXMinus: {true, 0, -1},
+		ActRotateYPlus:  {true, 1, 1},
+		ActRotateYMinus: {true, 1, -1},
+		ActRotateZPlus:  {true, 2, 1},
+		ActRotateZMinus: {true, 2, -1},
+	},
+	//same signs as Controller.Fly
+	"fly": {
+		ActThrustForward:  {false, 2, 1},
+		ActThrustBackward: {false, 2, -1},
+		ActThrustLeft:     {false, 0, -1},
+		ActThrustRight:    {false, 0, 1},
+		ActThrustUp:       {false, 1, 1},
+		ActThrustDown:     {false, 1, -1},
+		ActPitchUp:        {true, 0, 1},
+		ActPitchDown:      {true, 0, -1},
+		ActYawLeft:        {true, 1, 1},
+		ActYawRight:       {true, 1, -1},
+		ActRollLeft:       {true, 2, 1},
+		ActRollRight:      {true, 2, -1},
+	},
+	//X is right like fly thrust, Y is the turn to the left
+	"platform": {
+		ActRunForward:  {false, 2, 1},
+		ActRunBackward: {false, 2, -1},
+		ActStrafeLeft:  {false, 0, -1},
+		ActStrafeRight: {false, 0, 1},
+		ActTurnLeft:    {true, 1, 1},
+		ActTurnRight:   {true, 1, -1},
+	},
+	//platform mode's and X looking up like the fly pitch
+	"walk": {
+		ActRunForward:  {false, 2, 1},
+		ActRunBackward: {false, 2, -1},
+		ActStrafeLeft:  {false, 0, -1},
+		ActStrafeRight: {false, 0, 1},
+		ActTurnLeft:    {true, 1, 1},
+		ActTurnRight:   {true, 1, -1},
+		ActLookUp:      {true, 0, 1},
+		ActLookDown:    {true, 0, -1},
+	},
+	//fly mode's, a held thruster fires at full force
+	"spaceship": {
+		ActThrustForward:  {false, 2, 1},
+		ActThrustBackward: {false, 2, -1},
+		ActThrustLeft:     {false, 0, -1},
+		ActThrustRight:    {false, 0, 1},
+		ActThrustUp:       {false, 1, 1},
+		ActThrustDown:     {false, 1, -1},
+		ActPitchUp:        {true, 0, 1},
+		ActPitchDown:      {true, 0, -1},
+		ActYawLeft:        {true, 1, 1},
+		ActYawRight:       {true, 1, -1},
+		ActRollLeft:       {true, 2, 1},
+		ActRollRight:      {true, 2, -1},
+	},
+	//Z and Ctrl Z move the throttle, the turns are the control surfaces
+	"aircraft": {
+		ActThrustForward:  {false, 2, 1},
+		ActThrustBackward: {false, 2, -1},
+		ActPitchUp:        {true, 0, 1},
+		ActPitchDown:      {true, 0, -1},
+		ActYawLeft:        {true, 1, 1},
+		ActYawRight:       {true, 1, -1},
+		ActRollLeft:       {true, 2, 1},
+		ActRollRight:      {true, 2, -1},
+	},
+	//the pedal is Z, the brake pulling it back, the wheel turns left like the
+	//yaw and the handbrake is pulled up Y
+	"vehicle": {
+		ActThrottle:   {false, 2, 1},
+		ActBrake:      {false, 2, -1},
+		ActHandbrake:  {false, 1, 1},
+		ActSteerLeft:  {true, 1, 1},
+		ActSteerRight: {true, 1, -1},
+	},
+	//fly mode's, the thrust is the stick and the pitch and roll tilt it
+	"drone": {
+		ActThrustForward:  {false, 2, 1},
+		ActThrustBackward: {false, 2, -1},
+		ActThrustLeft:     {false, 0, -1},
+		ActThrustRight:    {false, 0, 1},
+		ActThrustUp:       {false, 1, 1},
+		ActThrustDown:     {false, 1, -1},
+		ActPitchUp:        {true, 0, 1},
+		ActPitchDown:      {true, 0, -1},
+		ActYawLeft:        {true, 1, 1},
+		ActYawRight:       {true, 1, -1},
+		ActRollLeft:       {true, 2, 1},
+		ActRollRight:      {true, 2, -1},
+	},
+}
+
+//Input is the keyboard as the simulation sees it: the keys held down for a
+//continuous action, and that action. The key up releases the action it was
+//pressed for, whatever the modifiers or the mode are by then.
+type Input struct {
+	held map[Key]Action
+}
+
+//Press holds a key down for an action
+func (in *Input) Press(key Key, a Action) {
+	if in.held == nil {
+		in.held = make(map[Key]Action)
+	}
+	in.held[key] = a
+}
+
+//Release lets go of a key and returns the action it was held for, false if
+//it was not held for one
+func (in *Input) Release(key Key) (Action, bool) {
+	a, ok := in.held[key]
+	delete(in.held, key)
+	return a, ok
+}
+
+//Held is true while some key holds the action down
+func (in *Input) Held(a Action) bool {
+	for _, h := range in.held {
+		if h == a {
+			return true
+		}
+	}
+	return false
+}
+
+//Clear lets go of every key
+func (in *Input) Clear() {
+	in.held = nil
+}
+
+//stick returns the deflection of the continuous axes of a mode from the held
+//actions, -1..1 per component, and which components are continuous at all
+func (in *Input) stick(b *Bindings, section string) (move, rot math32.Vector3, moveOn, rotOn [3]bool) {
+
+	for a, ax := range axisActions[section] {
+		if !b.Continuous(section, a) {
+			continue
+		}
+		v, on := &move, &moveOn
+		if ax.rot {
+			v, on = &rot, &rotOn
+		}
+		on[ax.comp] = true
+		if in.Held(a) {
+			v.SetComponent(ax.comp, v.Component(ax.comp)+ax.sign)
+		}
+	}
+	return
+}
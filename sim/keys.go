@@ -0,0 +1,159 @@
+package sim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//Key identifies a keyboard key. The values are the GLFW key codes used by
+//g3n's window package, so a window.Key converts directly with sim.Key(k)
+//without this package having to import (and link) GLFW.
+type Key int
+
+//letters and digits are their ASCII codes in GLFW
+const (
+	KeyA Key = iota + 65
+	KeyB
+	KeyC
+	KeyD
+	KeyE
+	KeyF
+	KeyG
+	KeyH
+	KeyI
+	KeyJ
+	KeyK
+	KeyL
+	KeyM
+	KeyN
+	KeyO
+	KeyP
+	KeyQ
+	KeyR
+	KeyS
+	KeyT
+	KeyU
+	KeyV
+	KeyW
+	KeyX
+	KeyY
+	KeyZ
+)
+
+const (
+	Key0 Key = iota + 48
+	Key1
+	Key2
+	Key3
+	Key4
+	Key5
+	Key6
+	Key7
+	Key8
+	Key9
+)
+
+const (
+	KeySpace Key = 32
+	KeyKP0   Key = 320
+)
+
+//ModifierKey is a bit set of held modifier keys, same values as window.ModifierKey
+type ModifierKey int
+
+const (
+	ModShift ModifierKey = 1 << iota
+	ModControl
+	ModAlt
+)
+
+//KeyEvent is the window free version of window.KeyEvent that the simulation understands
+type KeyEvent struct {
+	Key  Key
+	Mods ModifierKey
+}
+
+//String gives the same form ParseKey reads, e.g. "ctrl+shift+y"
+func (kev KeyEvent) String() string {
+	var sb strings.Builder
+	if kev.Mods&ModControl > 0 {
+		sb.WriteString("ctrl+")
+	}
+	if kev.Mods&ModShift > 0 {
+		sb.WriteString("shift+")
+	}
+	if kev.Mods&ModAlt > 0 {
+		sb.WriteString("alt+")
+	}
+	switch {
+	case kev.Key >= KeyA && kev.Key <= KeyZ:
+		sb.WriteByte(byte(kev.Key-KeyA) + 'a')
+	case kev.Key >= Key0 && kev.Key <= Key9:
+		sb.WriteByte(byte(kev.Key-Key0) + '0')
+	case kev.Key == KeyKP0:
+		sb.WriteString("kp0")
+	case kev.Key == KeySpace:
+		sb.WriteString("space")
+	default:
+		sb.WriteString(fmt.Sprintf("key%d", int(kev.Key)))
+	}
+	return sb.String()
+}
+
+//ParseKey reads a key description like "x", "ctrl+x", "shift+ctrl+y", "kp0" or "space",
+//any other key is its GLFW code, e.g. "key262", case does not matter
+func ParseKey(s string) (KeyEvent, error) {
+	var kev KeyEvent
+
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), "+")
+	for _, mod := range parts[:len(parts)-1] {
+		switch mod {
+		case "ctrl", "control":
+			kev.Mods |= ModControl
+		case "shift":
+			kev.Mods |= ModShift
+		case "alt":
+			kev.Mods |= ModAlt
+		default:
+			return kev, fmt.Errorf("unknown modifier %q in key %q", mod, s)
+		}
+	}
+
+	name := parts[len(parts)-1]
+	switch {
+	case name == "kp0":
+		kev.Key = KeyKP0
+	case name == "space":
+		kev.Key = KeySpace
+	case len(name) == 1 && name[0] >= 'a' && name[0] <= 'z':
+		kev.Key = KeyA + Key(name[0]-'a')
+	case len(name) == 1 && name[0] >= '0' && name[0] <= '9':
+		kev.Key = Key0 + Key(name[0]-'0')
+	case strings.HasPrefix(name, "key"):
+		code, err := strconv.Atoi(name[len("key"):])
+		if err != nil {
+			return kev, fmt.Errorf("unknown key %q", s)
+		}
+		kev.Key = Key(code)
+	default:
+		return kev, fmt.Errorf("unknown key %q", s)
+	}
+	return kev, nil
+}
+
+//ParseKeys reads a comma separated list of keys, see ParseKey
+func ParseKeys(s string) ([]KeyEvent, error) {
+	var kevs []KeyEvent
+	if strings.TrimSpace(s) == "" {
+		return kevs, nil
+	}
+	for _, k := range strings.Split(s, ",") {
+		kev, err := ParseKey(k)
+		if err != nil {
+			return nil, err
+		}
+		kevs = append(kevs, kev)
+	}
+	return kevs, nil
+}
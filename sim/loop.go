@@ -0,0 +1,241 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//maxFrameTime caps how much time one frame may feed the loop, after a long
+//stall (window dragged, debugger) we rather slow down than try to catch up
+const maxFrameTime = float32(0.25)
+
+//DefaultRate is the default number of simulation ticks per second
+const DefaultRate = float32(60)
+
+//transform is the part of a node the simulation moves
+type transform struct {
+	pos  math32.Vector3
+	quat math32.Quaternion
+}
+
+func (tr *transform) read(n *core.Node) {
+	tr.pos = n.Position()
+	tr.quat = n.Quaternion()
+}
+
+func (tr *transform) write(n *core.Node) {
+	n.SetPositionVec(&tr.pos)
+	n.SetQuaternionQuat(&tr.quat)
+}
+
+//Loop runs a Demo with a fixed time step whatever the frame rate. The frame
+//time is put in an accumulator and the demo ticks as many whole steps as fit,
+//keys are applied at the start of the next tick. Given the same keys at the
+//same ticks the movement comes out identical on every run.
+//
+//For rendering the nodes are placed between the last two ticks, by how far
+//the accumulator is into the next one, and put back before the next tick.
+type Loop struct {
+	Demo *Demo
+
+	rate, step  float32
+	accumulator float32
+	tick        uint64
+	queue       []ReplayEvent
+
+	//keys being recorded, and keys being played back
+	recording *Replay
+	playback  []ReplayEvent
+	playNext  int
+
+	//interpolation of the nodes, prev and curr are the last two ticks, shown
+	//is what was handed to the renderer
+	nodes             []*core.Node
+	prev, curr, shown []transform
+	interpolated      bool
+}
+
+//NewLoop returns a loop ticking d rate times a second, it panics unless the
+//rate is above 0 and finite, Advance would never catch up otherwise
+func NewLoop(d *Demo, rate float32) *Loop {
+
+	if !(rate > 0) || math.IsInf(float64(rate), 0) {
+		panic(fmt.Sprintf("sim: tick rate %v, want above 0 and finite", rate))
+	}
+	l := &Loop{Demo: d, rate: rate, step: 1 / rate}
+	l.nodes = []*core.Node{d.Gopher, d.SoloGopher, d.Camera, d.Sphere1, d.Sphere2}
+	l.prev = make([]transform, len(l.nodes))
+	l.curr = make([]transform, len(l.nodes))
+	l.shown = make([]transform, len(l.nodes))
+	l.snapshot(l.curr)
+	copy(l.prev, l.curr)
+	return l
+}
+
+//Step returns the fixed time step in seconds
+func (l *Loop) Step() float32 {
+	return l.step
+}
+
+//Tick returns the number of ticks run so far
+func (l *Loop) Tick() uint64 {
+	return l.tick
+}
+
+//OnKeyDown queues a key, it is handed to the demo at the start of the next tick
+func (l *Loop) OnKeyDown(kev KeyEvent) {
+	l.queue = append(l.queue, ReplayEvent{Key: kev})
+}
+
+//OnKeyUp queues a key release, like OnKeyDown
+func (l *Loop) OnKeyUp(kev KeyEvent) {
+	l.queue = append(l.queue, ReplayEvent{Key: kev, Up: true})
+}
+
+//OnMouseLook queues a mouse movement of dx, dy pixels, like OnKeyDown. The
+//movements of one tick add up to one, a replay need not hold every pixel.
+func (l *Loop) OnMouseLook(dx, dy float32) {
+
+	if n := len(l.queue); n > 0 && l.queue[n-1].Look {
+		l.queue[n-1].DX += dx
+		l.queue[n-1].DY += dy
+		return
+	}
+	l.queue = append(l.queue, ReplayEvent{Look: true, DX: dx, DY: dy})
+}
+
+//Record starts recording every key handed to the demo from now on
+func (l *Loop) Record() {
+	l.recording = &Replay{Rate: l.rate}
+}
+
+//Recording returns what was recorded so far, nil if not recording
+func (l *Loop) Recording() *Replay {
+	if l.recording == nil {
+		return nil
+	}
+	l.recording.Ticks = l.tick
+	return l.recording
+}
+
+//Play feeds the keys of a replay to the demo at their ticks, keys pressed
+//meanwhile are still handed on. A replay is meant to be played from the
+//first tick of a fresh demo at the rate it was recorded with.
+func (l *Loop) Play(r *Replay) {
+	l.playback = r.Events
+	l.playNext = 0
+}
+
+//Playing is true until all keys of the replay have been handed on
+func (l *Loop) Playing() bool {
+	return l.playNext < len(l.playback)
+}
+
+//Advance feeds one frame's delta time to the loop, runs the ticks that are
+//due and leaves the nodes interpolated for rendering. It returns the
+//interpolation factor, 0 is the last tick, 1 would be the next.
+func (l *Loop) Advance(dtime float32) float32 {
+
+	l.restore()
+
+	if dtime > maxFrameTime {
+		dtime = maxFrameTime
+	}
+	l.accumulator += dtime
+
+	for l.accumulator >= l.step {
+		l.accumulator -= l.step
+		copy(l.prev, l.curr)
+		l.runTick()
+	}
+
+	alpha := l.accumulator / l.step
+	l.interpolate(alpha)
+	return alpha
+}
+
+//RunTicks runs n ticks straight away, no frame time and no interpolation,
+//this is what the headless mode uses
+func (l *Loop) RunTicks(n int) {
+
+	l.restore()
+	for i := 0; i < n; i++ {
+		copy(l.prev, l.curr)
+		l.runTick()
+	}
+}
+
+//one fixed step of the simulation
+func (l *Loop) runTick() {
+
+	for l.Playing() && l.playback[l.playNext].Tick <= l.tick {
+		l.queue = append(l.queue, l.playback[l.playNext])
+		l.playNext++
+	}
+
+	for _, ev := range l.queue {
+		ev.Tick = l.tick
+		if l.recording != nil {
+			l.recording.Events = append(l.recording.Events, ev)
+		}
+		switch {
+		case ev.Look:
+			l.Demo.OnMouseLook(ev.DX, ev.DY)
+		case ev.Up:
+			l.Demo.OnKeyUp(ev.Key)
+		default:
+			l.Demo.OnKeyDown(ev.Key)
+		}
+	}
+	l.queue = l.queue[:0]
+
+	l.Demo.Update(l.step)
+	l.tick++
+	l.snapshot(l.curr)
+}
+
+func (l *Loop) snapshot(trs []transform) {
+	for i, n := range l.nodes {
+		trs[i].read(n)
+	}
+}
+
+//place the nodes between the last two ticks
+func (l *Loop) interpolate(alpha float32) {
+
+	for i, n := range l.nodes {
+		l.shown[i].pos = l.prev[i].pos
+		l.shown[i].pos.Lerp(&l.curr[i].pos, alpha)
+
+		l.shown[i].quat = l.prev[i].quat
+		l.shown[i].quat.Slerp(&l.curr[i].quat, alpha)
+
+		l.shown[i].write(n)
+	}
+	l.interpolated = true
+}
+
+//put the simulation state back on the nodes. Anything that moved a node
+//since it was interpolated (the orbit control moving the camera, say) wins,
+//its transform becomes the simulation state.
+func (l *Loop) restore() {
+
+	if !l.interpolated {
+		return
+	}
+	l.interpolated = false
+
+	var check transform
+	for i, n := range l.nodes {
+		check.read(n)
+		if !check.pos.Equals(&l.shown[i].pos) || !check.quat.Equals(&l.shown[i].quat) {
+			l.curr[i] = check
+			l.prev[i] = check
+			continue
+		}
+		l.curr[i].write(n)
+	}
+}
@@ -0,0 +1,127 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+//frames of uneven length, like a real render loop with the odd spike
+var jitteryFrames = []float32{0.016, 0.017, 0.033, 0.004, 0.016, 0.3, 0.011, 0.016, 0.05, 0.001}
+
+//flying with keys pressed during the run
+func runLoop(frames []float32, repeat int) (*Loop, []NodeState) {
+
+	l := NewLoop(NewHeadless(), DefaultRate)
+	script, _ := ParseKeys("m,z,z,y,p,h,v,r,n,ctrl+y")
+	for i := 0; i < repeat; i++ {
+		for j, dtime := range frames {
+			if k := i*len(frames) + j; k < len(script) {
+				l.OnKeyDown(script[k])
+			}
+			l.Advance(dtime)
+		}
+	}
+	l.restore()
+	return l, l.Demo.States()
+}
+
+func TestLoopDeterministic(t *testing.T) {
+
+	l1, first := runLoop(jitteryFrames, 20)
+	l2, second := runLoop(jitteryFrames, 20)
+
+	if l1.Tick() != l2.Tick() {
+		t.Fatalf("tick counts differ, %d and %d", l1.Tick(), l2.Tick())
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("runs differ: %v and %v", first[i], second[i])
+		}
+	}
+}
+
+//however the frames fall, the simulation state at a given tick is the same
+func TestLoopFrameTimeIndependent(t *testing.T) {
+
+	keys, _ := ParseKeys("m,z,z,y,p,h,v")
+
+	jittery := NewLoop(NewHeadless(), DefaultRate)
+	for _, kev := range keys {
+		jittery.OnKeyDown(kev)
+	}
+	for i := 0; i < 20; i++ {
+		for _, dtime := range jitteryFrames {
+			jittery.Advance(dtime)
+		}
+	}
+	jittery.restore()
+
+	steady := NewLoop(NewHeadless(), DefaultRate)
+	for _, kev := range keys {
+		steady.OnKeyDown(kev)
+	}
+	steady.RunTicks(int(jittery.Tick()))
+
+	got, want := jittery.Demo.States(), steady.Demo.States()
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("after %d ticks: %v, want %v", jittery.Tick(), got[i], want[i])
+		}
+	}
+}
+
+func TestLoopInterpolates(t *testing.T) {
+
+	l := NewLoop(NewHeadless(), 10)
+	keys, _ := ParseKeys("x,x,x,x")
+	for _, kev := range keys {
+		l.OnKeyDown(kev)
+	}
+
+	l.Advance(0.1)
+	if l.Tick() != 1 {
+		t.Fatalf("tick %d after one step, want 1", l.Tick())
+	}
+	//rendering runs a tick behind, alpha 0 shows the tick before the last one
+	l.restore()
+	last := l.Demo.Gopher.Position()
+
+	//a quarter into the next tick the gopher is shown a quarter of the way from the last tick
+	if alpha := l.Advance(0.125); !near(alpha, 0.25) {
+		t.Fatalf("alpha %v, want 0.25", alpha)
+	}
+	shown := l.Demo.Gopher.Position()
+	l.restore()
+	next := l.Demo.Gopher.Position()
+
+	if want := last.X + 0.25*(next.X-last.X); !near(shown.X, want) {
+		t.Errorf("shown at x %v, want %v between %v and %v", shown.X, want, last.X, next.X)
+	}
+
+	//and the interpolation does not leak into the simulation
+	steady := NewLoop(NewHeadless(), 10)
+	for _, kev := range keys {
+		steady.OnKeyDown(kev)
+	}
+	steady.RunTicks(2)
+	if p := steady.Demo.Gopher.Position(); p != next {
+		t.Errorf("simulated x %v, want %v", next, p)
+	}
+}
+
+//a rate that is not above 0 and finite would hang Advance or fill the demo
+//with NaN's, NewLoop refuses it
+func TestLoopRejectsBadRate(t *testing.T) {
+
+	inf := float32(math.Inf(1))
+	for _, rate := range []float32{0, -60, inf, float32(math.NaN())} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewLoop took a rate of %v", rate)
+				}
+			}()
+			NewLoop(NewHeadless(), rate)
+		}()
+	}
+}
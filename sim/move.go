@@ -0,0 +1,279 @@
+package sim
+
+//June 2022, Julius Schoen / R.M. Spicer,  GPL 3 license
+//written to show how to move objects smoothly with the g3n game engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/g3n/engine/math32"
+)
+
+//the movement routines, called once per frame with the frame's delta time
+func (d *Demo) Update(dtime float32) {
+
+	//This is the linear demo in translate mode that moves sphere1 around
+	d.vecAppVelocity = d.smoothApp.Smooth(&d.vecAppVelocityGoal, &d.vecAppVelocity, dtime)
+	d.usePos = d.Sphere1.Position() //sadly can't work with Position() directly...
+	d.Sphere1.SetPositionVec(d.usePos.Add(&d.vecAppVelocity))
+
+	d.Mover.Update(dtime)
+	d.updateQuarry(dtime)
+
+	//timed behaviours like the L slerp, on the same tick as everything else
+	d.Tasks.Update(dtime)
+
+	//the chase camera last, after everything it may look at has moved. It is
+	//a view, not a movement, so S and 0 do not stop it. Steering the camera
+	//itself there is nothing to chase, and N's blend moves the camera itself.
+	if d.chasing && !d.Switching() {
+		if d.Mover.Mover() == d.cameraMover {
+			d.Chase.Reset()
+		} else {
+			d.Chase.Target = d.Mover.Mover()
+			d.Chase.Update(dtime)
+		}
+	}
+}
+
+//key handler, the window side passes on every key it does not handle itself
+func (d *Demo) OnKeyDown(kev KeyEvent) {
+
+	//the mover first, it also takes the keys held for its continuous actions
+	if d.Mover.OnKeyDown(kev) {
+		return
+	}
+
+	a, ok := d.bindings.Lookup(ModeName(d.Mode()), kev)
+	if !ok {
+		return
+	}
+	d.OnAction(a)
+}
+
+//key up handler, ends continuous actions
+func (d *Demo) OnKeyUp(kev KeyEvent) {
+	d.Mover.OnKeyUp(kev)
+}
+
+//mouse handler, dx and dy pixels moved right and down, walk mode looks around with it
+func (d *Demo) OnMouseLook(dx, dy float32) {
+	d.Mover.OnMouseLook(dx, dy)
+}
+
+//OnAction does an action bound to a key, the mover's own actions first, see Bindings
+func (d *Demo) OnAction(a Action) {
+
+	if d.Mover.OnAction(a) {
+		return
+	}
+
+	switch a {
+
+	case ActStopRotation: //stop all rotations
+		d.Mover.StopRotation()
+
+	case ActApproachPlus: //positive linear Approach sphere1
+		d.vecAppVelocityGoal.SetZ(0.2)
+
+	case ActApproachMinus: //negative linear Approach sphere1
+		d.vecAppVelocityGoal.SetZ(-0.2)
+
+	case ActSnapLookAt, ActSlerpLookAt:
+		d.lookAt(a == ActSnapLookAt)
+
+	case ActToggleTracking: //keep looking at the target, wherever it goes
+		if d.Tracking() {
+			d.Tasks.Cancel(d.SoloGopher)
+		} else {
+			d.Tasks.Run(d.SoloGopher, d.Tracker)
+		}
+
+	case ActAutopilot: //fly to the LookAt target, or take the controls back
+		if d.Autopiloting() {
+			d.Tasks.Cancel(d.Mover.Node())
+		} else {
+			d.FlyTo(d.lookAtTarget)
+		}
+
+	case ActToggleOpponents: //the spheres and the blue gopher move, or stand
+		d.SetOpponents(!d.OpponentsMoving())
+
+	case ActToggleChase: //the camera follows the green gopher, or stays put
+		d.SetChasing(!d.chasing)
+
+	case ActToggleMode: //cycle the Movement types: Translate, Flying, Platform, Walk, Spaceship, Aircraft, Vehicle, Drone
+		opponents := d.OpponentsMoving()
+		d.Reset()
+		d.mvCnt++
+		d.Mover.SetMode(d.mvCnt % len(modeNames))
+		//walking is first person, through the camera's eyes
+		if d.Mode() == Walk {
+			d.Mover.SetMover(d.cameraMover)
+		}
+		//the opponents keep going, from their start places, U stops them
+		if opponents {
+			d.SetOpponents(true)
+		}
+
+	case ActToggleNode: //flip Node between green gopher and camera, see switch.go
+		d.toggleNode()
+
+	case ActReset: //reset
+		d.Reset()
+
+	case ActStop: //stop all motion
+		d.stop()
+
+	case ActPause: //toggle on/off, the autopilot would only fly on
+		if d.Autopiloting() {
+			d.Tasks.Cancel(d.Mover.Node())
+		}
+		d.Mover.TogglePause()
+
+	}
+}
+
+//a not beautiful, quick/dirty info message
+func (d *Demo) Info() string {
+
+	d.vecT1 = d.Sphere1.Position()
+	d.vecT2 = d.Sphere2.Position()
+	d.vecI = d.Mover.Node().Position()
+	d.vec1 = *d.vecT1.Sub(&d.vecI)
+	d.vec2 = *d.vecT2.Sub(&d.vecI)
+
+	var sb strings.Builder
+
+	//-----distance compare
+	//fast comparison
+	if d.vec1.LengthSq() >= d.vec2.LengthSq() {
+		sb.WriteString("big sphere closer\n")
+	} else {
+		sb.WriteString("small sphere closer\n")
+	}
+
+	//-----BackStab
+	//see theory https://www.youtube.com/watch?v=Q9FZllr6-wY&list=PLW3Zl3wyJwWOpdhYedlD-yCB7WQoHf-My&index=10
+	//and code https://www.youtube.com/watch?v=HXpSQ7yyu3o&list=PLW3Zl3wyJwWOpdhYedlD-yCB7WQoHf-My&index=11
+	d.vec1.Normalize()
+	d.vec2.Normalize()
+
+	//the mover knows which way is forward for a camera or a gopher
+	d.viewBasis = d.Mover.Basis()
+	d.vecViewForward = d.viewBasis.Forward
+
+	if d.vecViewForward.Dot(&d.vec1) < -0.8 {
+		sb.WriteString("small sphere backstab!\n")
+	}
+
+	if d.vecViewForward.Dot(&d.vec2) < -0.8 {
+		sb.WriteString("big sphere backstab!\n")
+	}
+
+	//let's see what magnitude velocity is...
+	vel := d.Mover.Velocity()
+	sb.WriteString(fmt.Sprintf("vel: %v\n", vel.Length()))
+
+	return sb.String()
+}
+
+//Stop all movements, slerps included, once stopped you can continue on by
+//pressing the movement keys again
+func (d *Demo) stop() {
+	d.Mover.Stop()
+	d.Tasks.CancelAll()
+
+	d.vecAppVelocity.Zero()
+	d.vecAppVelocityGoal.Zero()
+	d.smoothApp.Reset()
+}
+
+//Re-set all objects to start values
+func (d *Demo) Reset() {
+
+	d.stop()
+	d.Sphere1.SetPosition(-10, 4, 10)
+	d.Sphere2.SetPosition(0, 4, 10)
+	d.SoloGopher.SetPosition(-5, 4, 3)
+	d.Mover.SetMover(d.gopherMover)
+	d.Camera.Remove(d.Ship)
+
+	d.reset3DNormals()
+
+	d.Gopher.SetRotationVec(&zeroVector)
+	d.SoloGopher.SetRotationVec(&zeroVector)
+	d.Gopher.SetPosition(0, 0, 0)
+
+	d.Camera.SetRotationVec(&zeroVector)
+	d.Camera.SetPositionVec(&cameraVector)
+	d.Chase.Reset()
+
+	//the flying thrusts used to get reversed, and smudged, after a LookAt, since the
+	//fly basis comes from the world quaternion (motion.QuatBasis) that is history
+	d.Camera.LookAt(&zeroVector, &d.vecUpHat)
+
+}
+
+//LookAt's, direct (snap) and SLERP, to the next target
+func (d *Demo) lookAt(snap bool) {
+
+	d.getLookAtQuat()
+	switch {
+	case d.Tracking():
+		//the tracker turns to the new target at its own pace
+
+	case snap:
+		d.SoloGopher.SetQuaternionQuat(&d.toQuat)
+
+	default:
+		//pressing L again before it is done turns to the next target from where it is
+		d.Tasks.Run(d.SoloGopher, SlerpBy(d.SoloGopher, &d.toQuat, d.LookAtSlerp))
+	}
+}
+
+//Tracking is true while the blue gopher keeps turning to her target
+func (d *Demo) Tracking() bool {
+	return d.Tasks.Running(d.SoloGopher) == Task(d.Tracker)
+}
+
+//FlyTo has the autopilot fly whatever is steered to target, in fly mode,
+//see Autopilot
+func (d *Demo) FlyTo(target func(pos *math32.Vector3)) {
+	d.Autopilot.Target = target
+	d.Autopilot.Reset()
+	d.Tasks.Run(d.Mover.Node(), d.Autopilot)
+}
+
+//Autopiloting is true while the autopilot flies
+func (d *Demo) Autopiloting() bool {
+	return d.Tasks.Running(d.Mover.Node()) == Task(d.Autopilot)
+}
+
+//lookAtTarget puts the position of the current LookAt target in pos: the
+//small sphere, the large one or whatever is being steered
+func (d *Demo) lookAtTarget(pos *math32.Vector3) {
+
+	switch d.ToggleLookAtTarget % 3 {
+	case 1:
+		d.Sphere2.WorldPosition(pos)
+	case 2:
+		d.Mover.Node().WorldPosition(pos)
+	default: //no L pressed yet too
+		d.Sphere1.WorldPosition(pos)
+	}
+}
+
+//Does the work of changing the LookAt targets and getting the rotation
+//that has the blue gopher face the new one
+func (d *Demo) getLookAtQuat() {
+
+	d.ToggleLookAtTarget++
+	d.lookAtTarget(&d.vecLookAtTarget)
+
+	//Node.LookAt turns a node's negative Z at the target, right for a camera
+	//but the gopher faces positive Z, so it used to take a reversed vector
+	//to look the right way. LookAt knows the mover's forward axis instead.
+	d.toQuat = LookAt(d.soloMover, &d.vecLookAtTarget)
+}
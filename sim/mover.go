@@ -0,0 +1,80 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//Mover is anything a Controller can steer, a node plus the convention of
+//which of its local axes are forward and up. With that the same Fly and
+//Translate code drives a camera, a glTF model or the Ship without any sign
+//juggling.
+type Mover interface {
+	Node() *core.Node
+	Axes() *motion.Axes
+}
+
+//nodeMover is the plain Mover, a node with fixed axes
+type nodeMover struct {
+	node *core.Node
+	axes motion.Axes
+}
+
+func (m *nodeMover) Node() *core.Node {
+	return m.node
+}
+
+func (m *nodeMover) Axes() *motion.Axes {
+	return &m.axes
+}
+
+//NewMover returns a Mover for node whose forward and up are given by axes
+func NewMover(node *core.Node, axes motion.Axes) Mover {
+	return &nodeMover{node: node, axes: axes}
+}
+
+//ObjectMover is a properly oriented mesh, facing positive Z, like the gophers
+func ObjectMover(node *core.Node) Mover {
+	return NewMover(node, motion.ObjectAxes)
+}
+
+//CameraMover is a camera, looking down negative Z
+func CameraMover(node *core.Node) Mover {
+	return NewMover(node, motion.CameraAxes)
+}
+
+//worldUp is the up LookAt keeps a mover upright to
+var worldUp = math32.Vector3{X: 0, Y: 1, Z: 0}
+
+//LookAt returns the local rotation that turns m's forward axis to the world
+//position target, upright, for SetQuaternionQuat or a Slerp. It is
+//motion.Axes.LookAt for a node, so a mesh faces the target with its face,
+//not with the back of its head like Node.LookAt does.
+func LookAt(m Mover, target *math32.Vector3) math32.Quaternion {
+
+	node := m.Node()
+
+	//a node's world matrix is only as fresh as its parent's, update from the top
+	root := node
+	for root.Parent() != nil {
+		root = root.Parent().GetNode()
+	}
+	root.UpdateMatrixWorld()
+
+	var eye math32.Vector3
+	node.WorldPosition(&eye)
+	var world math32.Quaternion
+	node.WorldQuaternion(&world)
+	look := m.Axes().LookAtFrom(&world, &eye, target, &worldUp)
+
+	//a child is turned by its parent too, take that back off
+	if parent := node.Parent(); parent != nil {
+		var local math32.Quaternion
+		parent.GetNode().WorldQuaternion(&local)
+		local.Inverse()
+		local.Multiply(&look)
+		look = local
+	}
+	return look
+}
@@ -0,0 +1,56 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//LookAt must face the target also for a node on a turned parent
+func TestLookAtChild(t *testing.T) {
+
+	parent := core.NewNode()
+	parent.SetPosition(2, -1, 4)
+	parent.SetRotation(0.3, 1.1, -0.5)
+	child := core.NewNode()
+	child.SetPosition(1, 2, 0)
+	parent.Add(child)
+
+	target := math32.NewVector3(-6, 3, 8)
+	for _, m := range []Mover{ObjectMover(child), CameraMover(child)} {
+		q := LookAt(m, target)
+		child.SetQuaternionQuat(&q)
+
+		var eye math32.Vector3
+		child.WorldPosition(&eye)
+		want := *target
+		want.Sub(&eye).Normalize()
+		if b := NewController(m).Basis(); !b.Forward.AlmostEquals(&want, 1e-4) {
+			t.Errorf("axes %v: forward %v, want %v", *m.Axes(), b.Forward, want)
+		}
+	}
+}
+
+//Ctrl-L and L must both leave the blue gopher facing each target in turn
+func TestDemoLookAt(t *testing.T) {
+
+	for _, key := range []string{"ctrl+l", "l"} {
+		d := NewHeadless()
+		l := NewLoop(d, DefaultRate)
+		kev, _ := ParseKey(key)
+
+		for _, target := range []*core.Node{d.Sphere1, d.Sphere2, d.Gopher} {
+			l.OnKeyDown(kev)
+			l.RunTicks(int(2 * DefaultRate))
+
+			var eye, at math32.Vector3
+			d.SoloGopher.WorldPosition(&eye)
+			target.WorldPosition(&at)
+			want := *at.Sub(&eye).Normalize()
+			if b := NewController(d.soloMover).Basis(); !b.Forward.AlmostEquals(&want, 1e-4) {
+				t.Errorf("%s to %s: forward %v, want %v", key, target.Name(), b.Forward, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/math32"
+)
+
+//Jumping tunes how platform mode jumps and falls, see Controller.SetJumping
+type Jumping struct {
+	//Gravity pulls down along Y, in units per second squared
+	Gravity float32
+	//JumpSpeed is the upward speed a jump starts with, in units per second
+	JumpSpeed float32
+	//CoyoteTime is how long after running off an edge a jump still works,
+	//in seconds, 0 is not at all
+	CoyoteTime float32
+	//JumpBuffer is how long a jump pressed too early in the air is kept, it
+	//happens on landing if that is soon enough, in seconds
+	JumpBuffer float32
+	//Ground returns the height of the floor under x, z of the parent's space,
+	//false where there is none and the mover falls. nil is an endless floor
+	//at height 0.
+	Ground func(x, z float32) (float32, bool)
+}
+
+//GridGround is a square floor at height 0, size units wide and centred on
+//the origin, like the grid the demo draws
+func GridGround(size float32) func(x, z float32) (float32, bool) {
+	return func(x, z float32) (float32, bool) {
+		return 0, math32.Abs(x) <= size/2 && math32.Abs(z) <= size/2
+	}
+}
+
+//SetJumping changes how platform mode jumps and falls
+func (c *Controller) SetJumping(j Jumping) {
+	c.jumping = j
+}
+
+//Jumping returns how platform mode jumps and falls
+func (c *Controller) Jumping() Jumping {
+	return c.jumping
+}
+
+//OnGround is true while platform mode stands on the floor
+func (c *Controller) OnGround() bool {
+	return c.onGround
+}
+
+//this sets the motion vectors that will be used on foot, called from OnAction.
+//Running and turning are held keys by default, pressed they set a steady pace.
+func (c *Controller) Platform(a Action) bool {
+
+	switch a {
+
+	case ActJump:
+		c.jumpAsked, c.jumpAsk = true, 0
+
+	//X is to the right and Z forward like the fly thrust, see Update
+	case ActRunForward:
+		c.vecMovementGoal.Z += incrementLinearTranslate
+
+	case ActRunBackward:
+		c.vecMovementGoal.Z -= incrementLinearTranslate
+
+	case ActStrafeLeft:
+		c.vecMovementGoal.X -= incrementLinearTranslate
+
+	case ActStrafeRight:
+		c.vecMovementGoal.X += incrementLinearTranslate
+
+	case ActTurnLeft:
+		c.vecRotationGoal.Y += incrementRotTranslate
+
+	case ActTurnRight:
+		c.vecRotationGoal.Y -= incrementRotTranslate
+
+	default:
+		return false
+	}
+	return true
+}
+
+//platform mode, called from Update: run and turn on the floor, jump, and fall
+//under gravity until the floor stops it, all in units and radians per second
+func (c *Controller) updatePlatform(dtime float32) {
+
+	if c.frozen {
+		return
+	}
+	node := c.mover.Node()
+
+	//held keys drive their axes, the key presses set a steady pace
+	for i := 0; i < 3; i++ {
+		move, rot := c.vecMovementGoal.Component(i), c.vecRotationGoal.Component(i)
+		if c.moveOn[i] {
+			move = c.moveInput.Component(i) * runSpeed
+		}
+		if c.rotOn[i] {
+			rot = c.rotInput.Component(i) * turnSpeed
+		}
+		c.vecMovement.SetComponent(i, motion.Approach(move, c.vecMovement.Component(i), runRamp*dtime))
+		c.vecRotation.SetComponent(i, motion.Approach(rot, c.vecRotation.Component(i), turnRamp*dtime))
+	}
+
+	//turn about world up, not the mover's own, so it stays upright
+	c.quatRot.SetFromAxisAngle(&worldUp, c.vecRotation.Y*dtime)
+	c.quatView = node.Quaternion()
+	c.quatView.MultiplyQuaternions(&c.quatRot, &c.quatView)
+	node.SetQuaternionQuat(&c.quatView)
+
+	//run along the heading, forward and right flattened onto the floor
+	node.WorldQuaternion(&c.quatView)
+	c.viewBasis = c.mover.Axes().Basis(&c.quatView)
+	fwd := heading(&c.viewBasis)
+	var right math32.Vector3
+	right.CrossVectors(&fwd, &worldUp)
+	c.vecVelocity.X = fwd.X*c.vecMovement.Z + right.X*c.vecMovement.X
+	c.vecVelocity.Z = fwd.Z*c.vecMovement.Z + right.Z*c.vecMovement.X
+
+	//a jump works on the floor and for CoyoteTime after running off an edge,
+	//pressed too early in the air it is kept for JumpBuffer
+	if c.jumpAsked {
+		if c.canJump {
+			c.vecVelocity.Y = c.jumping.JumpSpeed
+			c.canJump, c.jumpAsked = false, false
+		} else if c.jumpAsk >= c.jumping.JumpBuffer {
+			c.jumpAsked = false
+		}
+		c.jumpAsk += dtime
+	}
+
+	//symbolically vecVelocity = vecVelocity + vecGravity * dtime, gravity only ever pulls down Y
+	c.vecVelocity.Y -= c.jumping.Gravity * dtime
+
+	c.usePos = node.Position()
+	was := c.usePos.Y
+	c.vecStep = c.vecVelocity
+	c.vecStep.MultiplyScalar(dtime)
+	c.usePos.Add(&c.vecStep)
+
+	//the floor stops a fall, but only one from above it, whoever fell past an
+	//edge does not climb back up through it
+	c.onGround = false
+	if h, ok := c.ground(c.usePos.X, c.usePos.Z); ok && c.usePos.Y <= h && was >= h {
+		c.usePos.Y = h
+		c.vecVelocity.Y = 0
+		c.onGround = true
+	}
+	node.SetPositionVec(&c.usePos)
+
+	if c.onGround {
+		c.canJump, c.offGround = true, 0
+	} else if c.offGround += dtime; c.offGround > c.jumping.CoyoteTime {
+		c.canJump = false
+	}
+}
+
+//heading returns a basis' forward flattened onto the floor, looking
+//straight up or down its up tells the way instead
+func heading(b *motion.Basis) math32.Vector3 {
+
+	fwd := b.Forward
+	fwd.Y = 0
+	if fwd.LengthSq() < 1e-6 {
+		//looking down up points ahead, looking up it points back
+		fwd = b.Up
+		if b.Forward.Y > 0 {
+			fwd.Negate()
+		}
+		fwd.Y = 0
+	}
+	return *fwd.Normalize()
+}
+
+//the height of the floor under x, z, false if there is none
+func (c *Controller) ground(x, z float32) (float32, bool) {
+	if c.jumping.Ground == nil {
+		return 0, true
+	}
+	return c.jumping.Ground(x, z)
+}
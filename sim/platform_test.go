@@ -0,0 +1,157 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+const platformTick = float32(1.0 / 60)
+
+//a controller in mode steering m with the default settings, a test changes
+//what it needs through the mode's setter, on foot the floor is endless
+func newInMode(mode int, m Mover) *Controller {
+	c := NewController(m)
+	c.SetMode(mode)
+	return c
+}
+
+func (c *Controller) ticks(n int) {
+	for i := 0; i < n; i++ {
+		c.Update(platformTick)
+	}
+}
+
+//a jump must go up about JumpSpeed²/2g and come back down onto the floor, never through it
+func TestJumpLands(t *testing.T) {
+
+	c := newInMode(Platform, ObjectMover(core.NewNode()))
+	c.ticks(1)
+	if !c.OnGround() {
+		t.Fatalf("not standing on the floor at %v", c.Node().Position())
+	}
+
+	c.OnKeyDown(KeyEvent{Key: KeySpace})
+	j := c.Jumping()
+	peak := float32(0)
+	for i := 0; i < 120; i++ {
+		c.ticks(1)
+		y := c.Node().Position().Y
+		if y < 0 {
+			t.Fatalf("tick %d: fell through the floor to %v", i, y)
+		}
+		peak = math32.Max(peak, y)
+	}
+
+	want := j.JumpSpeed * j.JumpSpeed / (2 * j.Gravity)
+	if math32.Abs(peak-want) > 0.1 {
+		t.Errorf("jumped %v high, want about %v", peak, want)
+	}
+	if !c.OnGround() || c.Node().Position().Y != 0 {
+		t.Errorf("did not land, at %v", c.Node().Position())
+	}
+}
+
+//off the edge a jump works for CoyoteTime, later it does not, a jump pressed
+//just before landing happens on landing if it is buffered
+func TestCoyoteTimeAndJumpBuffer(t *testing.T) {
+
+	tests := []struct {
+		name string
+		//seconds after running off the edge, or before landing, the jump is pressed
+		coyote bool
+		late   float32
+		want   bool
+	}{
+		{"coyote in time", true, 0.05, true},
+		{"coyote too late", true, 0.3, false},
+		{"buffered in time", false, 0.05, true},
+		{"buffered too early", false, 0.3, false},
+	}
+
+	for _, tt := range tests {
+		//a floor ending at x 0, or the floor a jump comes down on
+		c := newInMode(Platform, ObjectMover(core.NewNode()))
+		if tt.coyote {
+			j := c.Jumping()
+			j.Ground = func(x, z float32) (float32, bool) { return 0, x <= 0 }
+			c.SetJumping(j)
+		}
+		node := c.Node()
+
+		if tt.coyote {
+			//stand on the edge facing +X and run off it
+			node.SetPosition(-0.01, 0, 0)
+			node.RotateY(math32.Pi / 2)
+			c.ticks(1)
+			c.OnKeyDown(KeyEvent{Key: KeyZ})
+			for i := 0; i < 60 && c.OnGround(); i++ {
+				c.ticks(1)
+			}
+			if c.OnGround() {
+				t.Fatalf("%s: did not run off the edge, at %v", tt.name, node.Position())
+			}
+			c.ticks(int(tt.late / platformTick))
+			c.Platform(ActJump)
+			c.ticks(1)
+			if jumped := c.Velocity().Y > 0; jumped != tt.want {
+				t.Errorf("%s: jumped %v, want %v", tt.name, jumped, tt.want)
+			}
+			continue
+		}
+
+		//drop from a height that takes one second, fall ticks, to land
+		j := c.Jumping()
+		node.SetPosition(0, j.Gravity/2, 0)
+		const fall = 60
+		c.ticks(fall - int(tt.late/platformTick) - 1)
+		c.Platform(ActJump)
+		for i := 0; i < 30 && c.Velocity().Y <= 0; i++ {
+			c.ticks(1)
+		}
+		if jumped := c.Velocity().Y > 0; jumped != tt.want {
+			t.Errorf("%s: jumped %v, want %v", tt.name, jumped, tt.want)
+		}
+	}
+}
+
+//held run and turn keys move along the heading and turn about world up
+func TestPlatformRunAndTurn(t *testing.T) {
+
+	c := newInMode(Platform, ObjectMover(core.NewNode()))
+	node := c.Node()
+	node.RotateX(0.3)
+
+	c.OnKeyDown(KeyEvent{Key: KeyY})
+	c.ticks(30)
+	c.OnKeyUp(KeyEvent{Key: KeyY})
+	c.OnKeyDown(KeyEvent{Key: KeyZ})
+	c.ticks(60)
+
+	//the tilt is not turned into a roll or a climb, the run stays on the floor
+	b := c.Basis()
+	if b.Forward.Y > -0.29 || b.Forward.Y < -0.3 {
+		t.Errorf("turning changed the tilt, forward %v", b.Forward)
+	}
+	heading := b.Forward
+	heading.Y = 0
+	heading.Normalize()
+
+	moved := node.Position()
+	if moved.Y != 0 {
+		t.Errorf("ran off the floor to %v", moved)
+	}
+	if moved.Length() < 1 || moved.Normalize().Dot(&heading) < 0.99 {
+		t.Errorf("ran %v, want ahead along %v", node.Position(), heading)
+	}
+	if heading.X <= 0 {
+		t.Errorf("turning left from +Z should head to +X, heading %v", heading)
+	}
+
+	c.OnKeyUp(KeyEvent{Key: KeyZ})
+	c.ticks(30)
+	if v := c.Velocity(); v.Length() != 0 {
+		t.Errorf("still running at %v after letting go", v)
+	}
+}
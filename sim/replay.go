@@ -0,0 +1,219 @@
+package sim
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//replayHeader starts every replay file, version 1 files had no key ups and
+//version 2 files no mouse looks
+const (
+	replayHeader   = "g3nmovedemo replay 3"
+	replayHeaderV2 = "g3nmovedemo replay 2"
+	replayHeaderV1 = "g3nmovedemo replay 1"
+)
+
+//ReplayEvent is one key press, or release, or a mouse look of DX, DY pixels,
+//and the simulation tick it was applied at
+type ReplayEvent struct {
+	Tick   uint64
+	Key    KeyEvent
+	Up     bool
+	Look   bool
+	DX, DY float32
+}
+
+//Replay is a recorded session: every key and walk mode mouse look with its
+//tick, the tick rate and how many ticks were run. Played back on a fixed time
+//step it reproduces the session exactly, with or without a window. Mouse
+//orbiting of the camera is not part of it, nor are the key bindings and the
+//settings of the demo, its smoothing, gravity and mode tunings: play it back
+//with the same ones.
+//
+//The file is plain text so it can be attached to a bug report and read:
+//
+//	g3nmovedemo replay 3
+//	rate 60
+//	12 m
+//	80 ctrl+y
+//	95 z
+//	130 up z
+//	131 look 12 -3.5
+//	end 600
+type Replay struct {
+	Rate   float32
+	Events []ReplayEvent
+	Ticks  uint64
+}
+
+//Write saves the replay in its text form
+func (r *Replay) Write(w io.Writer) error {
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, replayHeader)
+	fmt.Fprintf(bw, "rate %v\n", r.Rate)
+	for _, ev := range r.Events {
+		switch {
+		case ev.Look:
+			fmt.Fprintf(bw, "%d look %v %v\n", ev.Tick, ev.DX, ev.DY)
+		case ev.Up:
+			fmt.Fprintf(bw, "%d up %v\n", ev.Tick, ev.Key)
+		default:
+			fmt.Fprintf(bw, "%d %v\n", ev.Tick, ev.Key)
+		}
+	}
+	fmt.Fprintf(bw, "end %d\n", r.Ticks)
+	return bw.Flush()
+}
+
+//Save writes the replay to a file
+func (r *Replay) Save(fpath string) error {
+
+	f, err := os.Create(fpath)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//ReadReplay reads a replay in the form Write produces
+func ReadReplay(rd io.Reader) (*Replay, error) {
+
+	r := &Replay{Rate: DefaultRate}
+	sc := bufio.NewScanner(rd)
+	line := 0
+	ended := false
+
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if line == 1 {
+			if text != replayHeader && text != replayHeaderV2 && text != replayHeaderV1 {
+				return nil, fmt.Errorf("replay: not a replay file, first line %q", text)
+			}
+			continue
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) == 4 && fields[1] == "look" {
+			ev, err := readLook(fields)
+			if err != nil {
+				return nil, fmt.Errorf("replay: line %d: %v", line, err)
+			}
+			if n := len(r.Events); n > 0 && ev.Tick < r.Events[n-1].Tick {
+				return nil, fmt.Errorf("replay: line %d: tick %d is before tick %d", line, ev.Tick, r.Events[n-1].Tick)
+			}
+			r.Events = append(r.Events, ev)
+			continue
+		}
+		up := len(fields) == 3 && fields[1] == "up"
+		if up {
+			fields = []string{fields[0], fields[2]}
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("replay: line %d: want a tick and a key, got %q", line, text)
+		}
+
+		switch fields[0] {
+		case "rate":
+			rate, err := strconv.ParseFloat(fields[1], 32)
+			//ParseFloat takes "NaN" and "inf" too, the loop could not tick at those
+			if err != nil || rate <= 0 || math.IsNaN(rate) || math.IsInf(rate, 0) {
+				return nil, fmt.Errorf("replay: line %d: bad rate %q", line, fields[1])
+			}
+			r.Rate = float32(rate)
+
+		case "end":
+			ticks, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("replay: line %d: bad end tick %q", line, fields[1])
+			}
+			r.Ticks = ticks
+			ended = true
+
+		default:
+			tick, err := strconv.ParseUint(fields[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("replay: line %d: bad tick %q", line, fields[0])
+			}
+			if n := len(r.Events); n > 0 && tick < r.Events[n-1].Tick {
+				return nil, fmt.Errorf("replay: line %d: tick %d is before tick %d", line, tick, r.Events[n-1].Tick)
+			}
+			kev, err := ParseKey(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("replay: line %d: %v", line, err)
+			}
+			r.Events = append(r.Events, ReplayEvent{Tick: tick, Key: kev, Up: up})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if line == 0 {
+		return nil, fmt.Errorf("replay: empty file")
+	}
+
+	//a replay cut short (the program crashed say) still plays up to its last key
+	if !ended && len(r.Events) > 0 {
+		r.Ticks = r.Events[len(r.Events)-1].Tick + 1
+	}
+	return r, nil
+}
+
+//readLook reads the fields of a "tick look dx dy" line
+func readLook(fields []string) (ReplayEvent, error) {
+
+	ev := ReplayEvent{Look: true}
+	tick, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return ev, fmt.Errorf("bad tick %q", fields[0])
+	}
+	dx, errX := strconv.ParseFloat(fields[2], 32)
+	dy, errY := strconv.ParseFloat(fields[3], 32)
+	if errX != nil || errY != nil {
+		return ev, fmt.Errorf("bad look %q %q", fields[2], fields[3])
+	}
+	ev.Tick, ev.DX, ev.DY = tick, float32(dx), float32(dy)
+	return ev, nil
+}
+
+//LoadReplay reads a replay file
+func LoadReplay(fpath string) (*Replay, error) {
+
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadReplay(f)
+}
+
+//PlayHeadless runs a replay on a fresh headless demo and returns the final
+//node states. The replay holds keys, not actions, so play it with the
+//bindings it was recorded with, nil is DefaultBindings.
+func PlayHeadless(b *Bindings, r *Replay) []NodeState {
+
+	return newHeadless(b).Play(r)
+}
+
+//Play runs a replay on the demo and returns the final node states, the demo
+//should be fresh and set up the way the replay was recorded
+func (d *Demo) Play(r *Replay) []NodeState {
+
+	l := NewLoop(d, r.Rate)
+	l.Play(r)
+	l.RunTicks(int(r.Ticks))
+	return d.States()
+}
@@ -0,0 +1,144 @@
+package sim
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+//held keys are recorded with their key up and replay the same
+func TestReplayHeldKeys(t *testing.T) {
+
+	b := DefaultBindings()
+	b.AnalogFly()
+	d := NewHeadless()
+	d.SetBindings(b)
+	l := NewLoop(d, DefaultRate)
+	l.Record()
+
+	l.OnKeyDown(KeyEvent{Key: KeyM})
+	l.RunTicks(3)
+	l.OnKeyDown(KeyEvent{Key: KeyZ})
+	l.OnKeyDown(KeyEvent{Key: KeyY, Mods: ModControl})
+	l.RunTicks(40)
+	l.OnKeyUp(KeyEvent{Key: KeyY})
+	l.RunTicks(20)
+	l.OnKeyUp(KeyEvent{Key: KeyZ})
+	l.RunTicks(50)
+	want := d.States()
+
+	var buf bytes.Buffer
+	if err := l.Recording().Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	r, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := PlayHeadless(b, r)
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("replay: %v, want %v", got[i], want[i])
+		}
+	}
+}
+
+//a windowed style session, recorded, written, read back and played headless
+//must end exactly where the session ended
+func TestReplayReproducesSession(t *testing.T) {
+
+	l := NewLoop(NewHeadless(), DefaultRate)
+	l.Record()
+
+	//thrusters reversed after L then N, as a bug report would have it
+	script, _ := ParseKeys("m,z,z,h,ctrl+l,l,v,n,l,h,l,shift+y,key262,p,ctrl+z,r")
+	for i := 0; i < 40; i++ {
+		for j, dtime := range jitteryFrames {
+			if k := i*len(jitteryFrames) + j; k%7 == 0 && k/7 < len(script) {
+				l.OnKeyDown(script[k/7])
+			}
+			l.Advance(dtime)
+		}
+	}
+	l.restore()
+	want := l.Demo.States()
+
+	var buf bytes.Buffer
+	if err := l.Recording().Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	r, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Events) != len(script) || r.Ticks != l.Tick() || r.Rate != DefaultRate {
+		t.Fatalf("read back %d keys over %d ticks at %v, want %d over %d at %v",
+			len(r.Events), r.Ticks, r.Rate, len(script), l.Tick(), DefaultRate)
+	}
+
+	got := PlayHeadless(nil, r)
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("replay: %v, want %v", got[i], want[i])
+		}
+	}
+}
+
+func TestReadReplay(t *testing.T) {
+
+	r, err := ReadReplay(strings.NewReader("g3nmovedemo replay 3\nrate 30\n# comment\n\n0 m\n5 ctrl+z\n5 shift+x\n9 up ctrl+z\n12 look 4 -2.5\nend 90\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ReplayEvent{
+		{Tick: 0, Key: KeyEvent{Key: KeyM}}, {Tick: 5, Key: KeyEvent{KeyZ, ModControl}},
+		{Tick: 5, Key: KeyEvent{KeyX, ModShift}}, {Tick: 9, Key: KeyEvent{KeyZ, ModControl}, Up: true},
+		{Tick: 12, Look: true, DX: 4, DY: -2.5},
+	}
+	if r.Rate != 30 || r.Ticks != 90 || len(r.Events) != len(want) {
+		t.Fatalf("got %+v", r)
+	}
+	for i := range want {
+		if r.Events[i] != want[i] {
+			t.Errorf("event %d: %v, want %v", i, r.Events[i], want[i])
+		}
+	}
+
+	//no end line, plays up to the last key, version 1 files still read
+	r, err = ReadReplay(strings.NewReader("g3nmovedemo replay 1\n7 z\n"))
+	if err != nil || r.Ticks != 8 || r.Rate != DefaultRate {
+		t.Errorf("cut short replay: %+v, %v", r, err)
+	}
+
+	for _, bad := range []string{
+		"",
+		"not a replay\n",
+		"g3nmovedemo replay 1\nrate fast\n",
+		"g3nmovedemo replay 1\nrate NaN\n",
+		"g3nmovedemo replay 1\nrate inf\n",
+		"g3nmovedemo replay 1\nrate +Inf\n",
+		"g3nmovedemo replay 1\nrate -30\n",
+		"g3nmovedemo replay 1\n5 z\n3 z\n",
+		"g3nmovedemo replay 1\n5 ctrl+nope\n",
+		"g3nmovedemo replay 1\n5\n",
+		"g3nmovedemo replay 2\n5 down z\n",
+		"g3nmovedemo replay 3\n5 look 4\n",
+		"g3nmovedemo replay 3\n5 look left 2\n",
+	} {
+		if _, err := ReadReplay(strings.NewReader(bad)); err == nil {
+			t.Errorf("no error reading %q", bad)
+		}
+	}
+}
+
+func TestKeyStringParses(t *testing.T) {
+
+	for _, kev := range []KeyEvent{
+		{KeyA, 0}, {KeyZ, ModControl}, {KeyY, ModShift | ModControl}, {Key0, 0}, {KeyKP0, ModAlt}, {KeySpace, ModShift}, {Key(262), 0},
+	} {
+		got, err := ParseKey(kev.String())
+		if err != nil || got != kev {
+			t.Errorf("ParseKey(%q) = %v, %v, want %v", kev.String(), got, err, kev)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+//Package sim holds the movement routines of the g3n move demo. It only
+//needs plain core.Node's, so it runs the very same movement code with or
+//without a window, renderer, font or texture.
+package sim
+
+//June 2022, Julius Schoen / R.M. Spicer,  GPL 3 license
+//written to show how to move objects smoothly with the g3n game engine
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//Demo holds the movement state, the window side only has to add pictures
+type Demo struct {
+	//our main character
+	Gopher *core.Node
+	//does not fly, but turns with the LookAt's
+	SoloGopher *core.Node
+	//the camera can be flown too, Ship is the "prow" indicator shown when it is
+	Camera, Ship *core.Node
+	//supporting actors, approach demo and LookAt targets
+	Sphere1, Sphere2 *core.Node
+
+	//the steerable mover, moves the green gopher or the camera
+	Mover                    *Controller
+	gopherMover, cameraMover Mover
+
+	//the keys, set with SetBindings
+	bindings *Bindings
+
+	//special vector to demonstrate smooth changes in velocity, and its smoother
+	vecAppVelocity, vecAppVelocityGoal math32.Vector3
+	smoothApp                          motion.Smoother
+
+	//timed behaviours, run on the simulation tick
+	Tasks Scheduler
+	//how the blue gopher's L LookAt turns, and how she tracks her target, set
+	//them after Init
+	LookAtSlerp SlerpSpec
+	Tracker     *Tracker
+
+	//the third person camera following whatever is steered, on while chasing,
+	//set it up after Init
+	Chase   *Chase
+	chasing bool
+
+	//how N blends the camera over before handing control over, set it after
+	//Init, and the blend under way
+	Switch    NodeSwitch
+	switching *switchTask
+
+	//G's autopilot, it flies whatever is steered in fly mode to the LookAt
+	//target, tune it after Init
+	Autopilot *Autopilot
+
+	//U's moving opponents, the small sphere, the big one and the blue
+	//gopher, tune them after Init, and whatever is steered as they see it
+	Opponents  []*Steering
+	quarry     motion.Agent
+	quarryNode *core.Node
+
+	//used for slerp'ing, the blue gopher as a mover knows where her face is
+	soloMover       Mover
+	toQuat          math32.Quaternion
+	vecLookAtTarget math32.Vector3
+
+	//counts M presses to cycle the movement modes
+	mvCnt int
+
+	//chooses three objects in order for blue gopher to LookAt
+	ToggleLookAtTarget int
+
+	//save some garbage collection
+	usePos, vecUpHat, vecViewForward math32.Vector3
+	vecT1, vecT2, vecI, vec1, vec2   math32.Vector3
+	viewBasis                        motion.Basis
+	//didn't use
+	//vecRightHat, vecScreenHat math32.Vector3
+}
+
+//"constant" vars
+var (
+	zeroVector   math32.Vector3 = *math32.NewVector3(0, 0, 0)
+	cameraVector math32.Vector3 = *math32.NewVector3(15, 4, -2)
+)
+
+//movement modes, see Mode()
+const (
+	Translate = iota
+	Fly
+	Platform
+	Walk
+	Spaceship
+	Aircraft
+	Vehicle
+	Drone
+)
+
+//modeNames are also the keymap sections of the modes
+var modeNames = []string{"translate", "fly", "platform", "walk", "spaceship", "aircraft", "vehicle", "drone"}
+
+//ModeName returns the name of a movement mode, e.g. "fly", of an unknown
+//mode "mode(n)", a section no key is bound in
+func ModeName(mode int) string {
+	if !validMode(mode) {
+		return fmt.Sprintf("mode(%d)", mode)
+	}
+	return modeNames[mode]
+}
+
+//validMode is true for the movement modes, Translate to Drone
+func validMode(mode int) bool {
+	return mode >= 0 && mode < len(modeNames)
+}
+
+const (
+	//translate mode works in units and radians per second
+	incrementLinearTranslate, incrementRotTranslate = float32(0.3), float32(1.2)
+	//fly mode works per frame
+	incrementRotFly = float32(0.004)
+	incrementLinear = float32(0.005)
+	incAcceleration = float32(2)
+
+	//how long the L slerp takes by default, in seconds, and how fast tracking
+	//turns, in radians per second
+	slerpDuration = float32(1)
+	trackSpeed    = float32(1.5)
+
+	//held keys, top speed and how fast it is reached: translate in units and
+	//radians per second, fly in thrust and turn per tick like the key presses
+	heldSpeedTranslate, heldRampTranslate  = float32(1.5), float32(3)
+	heldRotTranslate, heldRotRampTranslate = float32(2.4), float32(4.8)
+	heldThrustFly, heldThrustRampFly       = 10 * incrementLinear, float32(0.1)
+	heldRotFly, heldRotRampFly             = 5 * incrementRotFly, float32(0.04)
+
+	//platform mode, top run and turn speeds in units and radians per second and
+	//how fast they are reached, and the default Jumping
+	runSpeed, runRamp   = float32(3), float32(20)
+	turnSpeed, turnRamp = float32(2.4), float32(12)
+	gravity, jumpSpeed  = float32(9.8), float32(5)
+	coyoteTime          = float32(0.1)
+	jumpBuffer          = float32(0.1)
+
+	//walk mode, in units, radians and seconds, the look turns per pixel of mouse movement
+	walkSpeed, eyeHeight            = float32(2), float32(1.7)
+	lookSensitivity, lookPitchLimit = float32(0.003), float32(1.4)
+	headBob, stride                 = float32(0.05), float32(0.8)
+
+	//spaceship mode's default Thrusters, accelerating at 4 units per second
+	//squared and turning at 1.5 radians per second squared, 3 rolling, and
+	//the pulse a key press fires them for, in seconds
+	shipMass, shipForce = float32(10), float32(40)
+	shipTorque          = float32(15)
+	thrusterPulse       = float32(0.25)
+
+	//aircraft mode's throttle, a key press opens it by throttleStep and a held
+	//key by throttleRate a second, and how fast the controls move, in radians
+	//per second squared. A stalled wing keeps stallLift of its lift, and
+	//the coordinated turn goes no steeper than maxCoordinatedBank.
+	throttleStep, throttleRate = float32(0.1), float32(0.5)
+	controlRamp                = float32(6)
+	stallLift                  = float32(0.3)
+	maxCoordinatedBank         = float32(1.2)
+
+	//the default Airframe, it takes off at about 10 units per second and
+	//tops out at about 15 level
+	airThrust                         = float32(8)
+	airLift, airDrag, airInducedDrag  = float32(1), float32(0.035), float32(0.5)
+	airSideForce                      = float32(0.5)
+	stallAngle, stallSpeed, stallDrop = float32(0.3), float32(6), float32(1)
+	pitchRate, yawRate, rollRate      = float32(1.2), float32(0.5), float32(2)
+
+	//the default Handling, a car that tops out at 15 units per second and
+	//turns the wheel lock to lock in about half a second, and how far a key
+	//press moves the pedal or the wheel. Below stoppedSpeed the brake pedal
+	//reverses.
+	wheelbase, maxSteer, steerRate   = float32(1.2), float32(0.6), float32(2.5)
+	engine, brake, reverse           = float32(6), float32(12), float32(3)
+	pedalRate, carDrag               = float32(3), float32(0.4)
+	grip, handbrakeGrip, handbrakeOn = float32(25), float32(3), float32(4)
+	pedalStep, stoppedSpeed          = float32(0.25), float32(0.1)
+
+	//the default Hover, flat out at about 7 units per second, how far a key
+	//press moves a drone stick and how fast its turns follow their goals,
+	//in radians per second squared
+	maxTilt, levelGain      = float32(0.35), float32(6)
+	droneYawRate, climbRate = float32(1.5), float32(3)
+	maxThrust, altitudeGain = float32(20), float32(2)
+	droneDrag               = float32(0.5)
+	droneStep, droneRamp    = float32(0.25), float32(20)
+
+	//the chase camera's spring smooth times and look ahead, in seconds
+	chasePositionLag, chaseRotationLag = float32(0.3), float32(0.15)
+	chaseLookAhead                     = float32(0.5)
+
+	//how long N takes to blend the camera over to the next node, in seconds
+	switchDuration = float32(1)
+
+	//the autopilot's top turn rate and speed, in radians and units per
+	//second, and where it stops: clear of the big sphere, give or take
+	//autopilotRadius
+	autopilotMaxTurn, autopilotMaxSpeed = float32(1.5), float32(4)
+	autopilotStandOff, autopilotRadius  = float32(3), float32(0.1)
+
+	//the opponents, in units and seconds: the small sphere patrols, the big
+	//one wanders within about wanderRange of its place and runs once closer
+	//than evadeRadius, the blue gopher hunts turning hunterTurn radians a
+	//second. They look avoidLookAhead seconds ahead for what is in the way.
+	patrolSpeed, wanderSpeed, hunterSpeed = float32(2), float32(1.5), float32(1.8)
+	opponentAccel, hunterTurn             = float32(3), float32(3)
+	wanderRange, evadeRadius              = float32(8), float32(6)
+	avoidLookAhead                        = float32(1.5)
+)
+
+//the small sphere's patrol round the middle of the grid, and where the big
+//one wanders about
+var (
+	patrolPath = []math32.Vector3{{X: -10, Y: 4, Z: 10}, {X: -10, Y: 4, Z: -10}, {X: 10, Y: 4, Z: -10}, {X: 10, Y: 4, Z: 10}}
+	bigHome    = math32.Vector3{X: 0, Y: 4, Z: 10}
+)
+
+//the autopilot's default gains, it turns quickly and slows down early
+var (
+	autopilotTurn  = motion.Gains{Kp: 2, Ki: 0, Kd: 0.1}
+	autopilotSpeed = motion.Gains{Kp: 1, Ki: 0, Kd: 0.2}
+)
+
+//chaseOffset puts the chase camera above and behind what it follows
+var chaseOffset = math32.Vector3{X: 0, Y: 1.5, Z: -5}
+
+//shipInertia is spaceship mode's default moment of inertia, about the pitch,
+//yaw and roll axes, it rolls more easily than it turns
+var shipInertia = math32.Vector3{X: 10, Y: 10, Z: 5}
+
+//GridSize is how wide the floor grid is, platform mode falls off its edges
+const GridSize = 50
+
+//NewHeadless builds the demo from bare nodes placed and scaled the way the
+//windowed demo places its models, nothing is loaded from the data directory
+func NewHeadless() *Demo {
+
+	d := &Demo{
+		Gopher:     core.NewNode(),
+		SoloGopher: core.NewNode(),
+		Camera:     core.NewNode(),
+		Ship:       core.NewNode(),
+		Sphere1:    core.NewNode(),
+		Sphere2:    core.NewNode(),
+	}
+
+	d.Gopher.SetName("gopher")
+	d.Gopher.SetScale(0.3, 0.3, 0.3)
+
+	d.SoloGopher.SetName("sologopher")
+	d.SoloGopher.SetScale(0.6, 0.6, 0.6)
+	d.SoloGopher.SetPosition(-5, 4, 3)
+
+	d.Camera.SetName("camera")
+
+	//same offsets as GameApp.setupShip
+	d.Ship.SetName("ship")
+	d.Ship.SetPosition(0, -1, -4)
+	d.Ship.RotateX(-0.1)
+	d.Ship.RotateY(math.Pi / 2)
+	d.Ship.RotateZ(math.Pi / 6)
+
+	d.Sphere1.SetName("sphere1")
+	d.Sphere2.SetName("sphere2")
+	d.Sphere2.SetPosition(0, 4, 10)
+
+	d.Init()
+	return d
+}
+
+//Init starts the demo in translate mode with everything in its start position,
+//the node fields must be set before calling it
+func (d *Demo) Init() {
+
+	d.gopherMover = ObjectMover(d.Gopher)
+	d.cameraMover = CameraMover(d.Camera)
+	d.soloMover = ObjectMover(d.SoloGopher)
+	d.Mover = NewController(d.gopherMover)
+	d.smoothApp = sphereSmoothing.smoother(SmoothApproach)
+	if d.bindings == nil {
+		d.bindings = DefaultBindings()
+	}
+	d.Mover.SetBindings(d.bindings)
+	d.mvCnt = 0
+	d.ToggleLookAtTarget = -1
+	d.LookAtSlerp = SlerpSpec{Duration: slerpDuration}
+	d.Tracker = &Tracker{Mover: d.soloMover, Target: d.lookAtTarget, Speed: trackSpeed}
+	d.Switch = NodeSwitch{Duration: switchDuration, Ease: motion.EaseInOut}
+	d.Autopilot = &Autopilot{Controller: d.Mover, Target: d.lookAtTarget,
+		Turn: autopilotTurn, MaxTurn: autopilotMaxTurn, Speed: autopilotSpeed, MaxSpeed: autopilotMaxSpeed,
+		StandOff: autopilotStandOff, Radius: autopilotRadius}
+	d.initOpponents()
+	d.Chase = &Chase{Camera: d.cameraMover, Offset: chaseOffset,
+		PositionLag: chasePositionLag, RotationLag: chaseRotationLag, LookAhead: chaseLookAhead}
+	jumping := d.Mover.Jumping()
+	jumping.Ground = GridGround(GridSize)
+	d.Mover.SetJumping(jumping)
+	d.Reset()
+}
+
+//SetBindings changes the keys of the demo, see LoadBindings
+func (d *Demo) SetBindings(b *Bindings) {
+	d.bindings = b
+	d.Mover.SetBindings(b)
+}
+
+//Bindings returns the keys of the demo
+func (d *Demo) Bindings() *Bindings {
+	return d.bindings
+}
+
+//SetSmoothing chooses how fly mode and the sphere1 demo ease toward their goals
+func (d *Demo) SetSmoothing(s Smoothing) {
+	d.Mover.SetSmoothing(s)
+	d.smoothApp = sphereSmoothing.smoother(s)
+}
+
+//SetChasing turns the chase camera on or off, it springs from wherever the
+//camera is
+func (d *Demo) SetChasing(on bool) {
+	d.chasing = on
+	d.Chase.Reset()
+}
+
+//Chasing is true while the camera follows whatever is steered
+func (d *Demo) Chasing() bool {
+	return d.chasing
+}
+
+//Mode returns the current movement mode, Translate, Fly, Platform, Walk, Spaceship, Aircraft, Vehicle or Drone
+func (d *Demo) Mode() int {
+	return d.Mover.Mode()
+}
+
+//Current returns the node currently being steered, the green gopher or the camera
+func (d *Demo) Current() *core.Node {
+	return d.Mover.Node()
+}
+
+//Re-set the up, right, screen normals
+func (d *Demo) reset3DNormals() {
+	//These "constants" can be changed when they are used, re-set them
+	//for now I only use the vecUpHat....
+	//vecRightHat.Set(1, 0, 0)
+	d.vecUpHat.Set(0, 1, 0)
+	//vecScreenHat.Set(0, 0, 1)
+}
@@ -0,0 +1,62 @@
+package sim
+
+import (
+	"fmt"
+
+	"github.com/Juuliuus/g3nmovedemo/motion"
+)
+
+//Smoothing chooses how fly mode and the sphere1 demo ease toward their goals
+type Smoothing int
+
+//the smoothings, see motion.Smoother
+const (
+	//SmoothApproach steps toward the goal at a fixed rate, the demo's original
+	SmoothApproach Smoothing = iota
+	//SmoothDamp is a critically damped spring, eases in and out
+	SmoothDamp
+	//SmoothDecay closes a fixed fraction of the gap every second
+	SmoothDecay
+)
+
+var smoothingNames = []string{"approach", "damp", "decay"}
+
+func (s Smoothing) String() string {
+	if s < 0 || int(s) >= len(smoothingNames) {
+		return fmt.Sprintf("smoothing(%d)", int(s))
+	}
+	return smoothingNames[s]
+}
+
+//ParseSmoothing returns the smoothing of a name, approach, damp or decay
+func ParseSmoothing(name string) (Smoothing, error) {
+	for i, n := range smoothingNames {
+		if n == name {
+			return Smoothing(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown smoothing %q, want one of %v", name, smoothingNames)
+}
+
+//smoothParams are the settings of each smoothing for one smoothed value
+type smoothParams struct {
+	approachRate, smoothTime, decayRate float32
+}
+
+var (
+	//the approach rates are what the demo always used
+	flyRotSmoothing  = smoothParams{approachRate: 0.2, smoothTime: 0.25, decayRate: 8}
+	flyMoveSmoothing = smoothParams{approachRate: 1, smoothTime: 0.25, decayRate: 8}
+	sphereSmoothing  = smoothParams{approachRate: 1, smoothTime: 0.5, decayRate: 4}
+)
+
+//smoother returns a fresh smoother of kind s with these settings
+func (p smoothParams) smoother(s Smoothing) motion.Smoother {
+	switch s {
+	case SmoothDamp:
+		return &motion.Damper{SmoothTime: p.smoothTime}
+	case SmoothDecay:
+		return &motion.Decayer{Rate: p.decayRate}
+	}
+	return &motion.Approacher{Rate: p.approachRate}
+}
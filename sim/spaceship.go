@@ -0,0 +1,158 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/math32"
+)
+
+//Thrusters tunes spaceship mode, see Controller.SetThrusters
+type Thrusters struct {
+	//Mass is what the linear thrusters push, Force how hard: the acceleration
+	//is Force/Mass units per second squared along any of the mover's axes
+	Mass, Force float32
+	//Inertia is the moment of inertia about the right, up and back axes, the
+	//pitch, yaw and roll axes, Torque how hard the rotation thrusters turn
+	//about each of them
+	Inertia math32.Vector3
+	Torque  float32
+}
+
+//SetThrusters changes the spaceship's mass, inertia and thrusters
+func (c *Controller) SetThrusters(t Thrusters) {
+	c.thrusters = t
+}
+
+//Thrusters returns the spaceship's mass, inertia and thrusters
+func (c *Controller) Thrusters() Thrusters {
+	return c.thrusters
+}
+
+//SetFlightAssist turns spaceship mode's flight assist on or off, with it the
+//thrusters brake whatever motion no key is thrusting along
+func (c *Controller) SetFlightAssist(on bool) {
+	c.assist = on
+}
+
+//FlightAssist is true while spaceship mode's flight assist is on
+func (c *Controller) FlightAssist() bool {
+	return c.assist
+}
+
+//AngularVelocity returns spaceship mode's spin, in radians per second of
+//pitch, yaw and roll about the mover's own axes
+func (c *Controller) AngularVelocity() math32.Vector3 {
+	return c.vecRotation
+}
+
+//this fires the thrusters in spaceship mode, called from OnAction. Thrusters
+//are held keys by default, pressed they fire a short pulse.
+func (c *Controller) Spaceship(a Action) bool {
+
+	if a == ActFlightAssist {
+		c.assist = !c.assist
+		return true
+	}
+	ax, ok := axisActions[ModeName(Spaceship)][a]
+	if !ok {
+		return false
+	}
+	//a pulse is the velocity the thruster gives in thrusterPulse seconds
+	if ax.rot {
+		i := ax.comp
+		c.vecRotation.SetComponent(i, c.vecRotation.Component(i)+ax.sign*c.angularAccel(i)*thrusterPulse)
+		return true
+	}
+	var push math32.Vector3
+	push.SetComponent(ax.comp, ax.sign*c.linearAccel()*thrusterPulse)
+	node := c.mover.Node()
+	node.WorldQuaternion(&c.quatView)
+	c.viewBasis = c.mover.Axes().Basis(&c.quatView)
+	push = c.viewBasis.Velocity(&push)
+	c.vecVelocity.Add(&push)
+	return true
+}
+
+//spaceship mode, called from Update: the thrusters accelerate and spin the
+//mover and nothing slows it down again, unless the flight assist is on. The
+//velocity is in world units per second, the spin in radians per second about
+//the mover's own axes.
+func (c *Controller) updateSpaceship(dtime float32) {
+
+	if c.frozen {
+		return
+	}
+	node := c.mover.Node()
+	node.WorldQuaternion(&c.quatView)
+	c.viewBasis = c.mover.Axes().Basis(&c.quatView)
+
+	//I dw/dt = torque - w x Iw, Euler's equations: a spin about anything but
+	//a principal axis wobbles, as it would out there. Right, up and back are
+	//a right handed frame, see motion.Axes.Basis, so they hold here.
+	var torque, iw, gyro math32.Vector3
+	torque.Copy(&c.rotInput).MultiplyScalar(c.thrusters.Torque)
+	iw.Copy(&c.vecRotation).Multiply(&c.thrusters.Inertia)
+	gyro.CrossVectors(&c.vecRotation, &iw)
+	torque.Sub(&gyro)
+	for i := 0; i < 3; i++ {
+		if in := c.thrusters.Inertia.Component(i); in > 0 {
+			c.vecRotation.SetComponent(i, c.vecRotation.Component(i)+torque.Component(i)/in*dtime)
+		}
+	}
+
+	//force = mass * acceleration along the mover's axes, X right, Y up, Z forward
+	var accel math32.Vector3
+	accel.Copy(&c.moveInput).MultiplyScalar(c.linearAccel())
+	accel = c.viewBasis.Velocity(&accel)
+	accel.MultiplyScalar(dtime)
+	c.vecVelocity.Add(&accel)
+
+	//the flight assist fires the thrusters against whatever no key is firing
+	//them for, no harder than they can
+	if c.assist {
+		c.assistBrake(dtime)
+	}
+
+	c.quatRot = node.Quaternion()
+	c.mover.Axes().Rotate(&c.quatRot, c.vecRotation.X*dtime, c.vecRotation.Y*dtime, c.vecRotation.Z*dtime)
+	node.SetQuaternionQuat(&c.quatRot)
+
+	c.vecStep = c.vecVelocity
+	c.vecStep.MultiplyScalar(dtime)
+	c.usePos = node.Position()
+	node.SetPositionVec(c.usePos.Add(&c.vecStep))
+}
+
+//brake the motion along, and the spin about, the mover's axes no key is thrusting
+func (c *Controller) assistBrake(dtime float32) {
+
+	axes := [3]*math32.Vector3{&c.viewBasis.Right, &c.viewBasis.Up, &c.viewBasis.Forward}
+	for i, axis := range axes {
+		if c.moveInput.Component(i) == 0 {
+			v := c.vecVelocity.Dot(axis)
+			dv := motion.Approach(0, v, c.linearAccel()*dtime) - v
+			step := *axis
+			c.vecVelocity.Add(step.MultiplyScalar(dv))
+		}
+		if c.rotInput.Component(i) == 0 {
+			w := c.vecRotation.Component(i)
+			c.vecRotation.SetComponent(i, motion.Approach(0, w, c.angularAccel(i)*dtime))
+		}
+	}
+}
+
+//the linear thrusters' acceleration, units per second squared
+func (c *Controller) linearAccel() float32 {
+	if c.thrusters.Mass <= 0 {
+		return 0
+	}
+	return c.thrusters.Force / c.thrusters.Mass
+}
+
+//the rotation thrusters' angular acceleration about axis i, radians per second squared
+func (c *Controller) angularAccel(i int) float32 {
+	in := c.thrusters.Inertia.Component(i)
+	if in <= 0 {
+		return 0
+	}
+	return c.thrusters.Torque / in
+}
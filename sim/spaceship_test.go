@@ -0,0 +1,98 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//a held thruster accelerates at Force/Mass and once let go the ship coasts
+//on at that speed for good, twice the mass gets half the speed
+func TestSpaceshipKeepsMomentum(t *testing.T) {
+
+	for _, mass := range []float32{10, 20} {
+		c := newInMode(Spaceship, ObjectMover(core.NewNode()))
+		th := c.Thrusters()
+		th.Mass = mass
+		c.SetThrusters(th)
+		c.OnKeyDown(KeyEvent{Key: KeyZ})
+		c.ticks(60)
+		c.OnKeyUp(KeyEvent{Key: KeyZ})
+
+		want := c.Thrusters().Force / mass
+		if v := c.Velocity(); math32.Abs(v.Z-want) > 1e-3 || math32.Abs(v.X)+math32.Abs(v.Y) > 1e-5 {
+			t.Errorf("mass %v: a second of thrust got to %v, want %v along +Z", mass, v, want)
+		}
+		start := c.Node().Position()
+		c.ticks(600)
+		if v := c.Velocity(); math32.Abs(v.Z-want) > 1e-3 {
+			t.Errorf("mass %v: slowed down to %v coasting", mass, v)
+		}
+		if moved := c.Node().Position().Z - start.Z; math32.Abs(moved-10*want) > 0.01 {
+			t.Errorf("mass %v: coasted %v in 10 seconds, want %v", mass, moved, 10*want)
+		}
+	}
+}
+
+//a spin keeps on too, turning doesn't change the way the ship drifts, and
+//a pressed thruster fires a pulse
+func TestSpaceshipSpin(t *testing.T) {
+
+	c := newInMode(Spaceship, ObjectMover(core.NewNode()))
+	c.OnAction(ActThrustForward)
+	c.OnKeyDown(KeyEvent{Key: KeyY})
+	c.ticks(30)
+	c.OnKeyUp(KeyEvent{Key: KeyY})
+
+	th := c.Thrusters()
+	if w, want := c.AngularVelocity().Y, th.Torque/th.Inertia.Y/2; math32.Abs(w-want) > 1e-3 {
+		t.Errorf("half a second of yaw spins at %v, want %v", w, want)
+	}
+	drift := c.Velocity()
+	if want := th.Force / th.Mass * thrusterPulse; math32.Abs(drift.Length()-want) > 1e-4 {
+		t.Errorf("a pulse got to %v, want %v", drift.Length(), want)
+	}
+	before := c.Basis().Forward
+	c.ticks(60)
+	if v := c.Velocity(); !v.Equals(&drift) {
+		t.Errorf("turning changed the drift from %v to %v", drift, v)
+	}
+	if after := c.Basis().Forward; after.Dot(&before) > 0.99 || after.X <= before.X {
+		t.Errorf("yaw left from %v turned to %v", before, after)
+	}
+}
+
+//the flight assist brakes whatever motion and spin no key is thrusting for,
+//no harder than the thrusters can, and lets the held ones be
+func TestFlightAssist(t *testing.T) {
+
+	c := newInMode(Spaceship, ObjectMover(core.NewNode()))
+	c.OnKeyDown(KeyEvent{Key: KeyZ})
+	c.OnKeyDown(KeyEvent{Key: KeyR})
+	c.ticks(60)
+	c.OnKeyUp(KeyEvent{Key: KeyR})
+	if !c.OnAction(ActFlightAssist) || !c.FlightAssist() {
+		t.Fatalf("A did not turn the flight assist on")
+	}
+	c.OnKeyDown(KeyEvent{Key: KeyH})
+	c.ticks(60)
+	c.OnKeyUp(KeyEvent{Key: KeyH})
+	c.OnKeyUp(KeyEvent{Key: KeyZ})
+
+	//still thrusting forward and left it did not brake, the roll stopped
+	//in a second at 3 radians per second squared
+	if w := c.AngularVelocity(); w.Length() > 1e-4 {
+		t.Errorf("still spinning at %v", w)
+	}
+	v := c.Velocity()
+	b := c.Basis()
+	if fwd, left := v.Dot(&b.Forward), -v.Dot(&b.Right); fwd < 7.9 || left < 3 {
+		t.Errorf("braked while thrusting, %v forward and %v left", fwd, left)
+	}
+
+	c.ticks(120)
+	if v := c.Velocity(); v.Length() > 1e-4 {
+		t.Errorf("the flight assist left it drifting at %v", v)
+	}
+}
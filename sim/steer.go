@@ -0,0 +1,123 @@
+package sim
+
+import (
+	"math/rand"
+
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/math32"
+)
+
+//Steering is a Task that moves a node the way a steering behaviour asks, see
+//motion.Behaviour. Agent is the node as the behaviours see it, others may
+//point at it to pursue it or steer round it. It runs until it is cancelled,
+//run it on the node with a Scheduler.
+type Steering struct {
+	Mover     Mover
+	Agent     motion.Agent
+	Behaviour motion.Behaviour
+	//TurnSpeed turns the mover to face the way it goes, at most this many
+	//radians per second, 0 does not turn it
+	TurnSpeed float32
+
+	ahead math32.Vector3
+}
+
+//Update moves the node one tick, with nothing to say the behaviour lets
+//it come to a stop, it never finishes
+func (st *Steering) Update(dtime float32) bool {
+
+	//anything else may have moved the node since the last tick
+	node := st.Mover.Node()
+	st.Agent.Position = node.Position()
+	desired, _ := st.Behaviour.Desired(&st.Agent, dtime)
+	st.Agent.Steer(&desired, dtime)
+	node.SetPositionVec(&st.Agent.Position)
+
+	if st.TurnSpeed > 0 && st.Agent.Velocity.LengthSq() > 1e-6 {
+		st.ahead.AddVectors(&st.Agent.Position, &st.Agent.Velocity)
+		look := LookAt(st.Mover, &st.ahead)
+		cur := node.Quaternion()
+		look = motion.ApproachQuat(&look, &cur, st.TurnSpeed*dtime)
+		node.SetQuaternionQuat(&look)
+	}
+	return false
+}
+
+//the opponents U sets going: the small sphere patrols the grid, the big one
+//wanders about its place and runs from whatever is steered when it comes
+//close, the blue gopher hunts it down. They steer round each other, and the
+//blue gopher round her quarry too, rather than ram it.
+func (d *Demo) initOpponents() {
+
+	small := &Steering{Mover: ObjectMover(d.Sphere1),
+		Agent: motion.Agent{MaxSpeed: patrolSpeed, MaxAccel: opponentAccel}}
+	big := &Steering{Mover: ObjectMover(d.Sphere2),
+		Agent: motion.Agent{MaxSpeed: wanderSpeed, MaxAccel: opponentAccel}}
+	solo := &Steering{Mover: d.soloMover, TurnSpeed: hunterTurn,
+		Agent: motion.Agent{MaxSpeed: hunterSpeed, MaxAccel: opponentAccel}}
+
+	smallOb := motion.Obstacle{Center: &small.Agent.Position, Radius: 1}
+	bigOb := motion.Obstacle{Center: &big.Agent.Position, Radius: 2}
+	soloOb := motion.Obstacle{Center: &solo.Agent.Position, Radius: 1}
+	quarryOb := motion.Obstacle{Center: &d.quarry.Position, Radius: 1.5}
+	avoid := func(obs ...motion.Obstacle) *motion.AvoidObstacles {
+		return &motion.AvoidObstacles{Obstacles: obs, LookAhead: avoidLookAhead, Clearance: 0.5}
+	}
+
+	small.Behaviour = motion.Priority{avoid(bigOb, soloOb),
+		&motion.FollowPath{Points: patrolPath, Radius: 1, Loop: true}}
+	big.Behaviour = motion.Priority{avoid(smallOb, soloOb),
+		&motion.Evade{Target: &d.quarry, MaxPrediction: 1, Radius: evadeRadius},
+		motion.Blend{
+			{Behaviour: &motion.Wander{Distance: 3, Radius: 1.5, Jitter: 4, Rand: rand.New(rand.NewSource(1))}, Weight: 1},
+			{Behaviour: &motion.Arrive{Target: &bigHome, SlowRadius: wanderRange}, Weight: 1},
+		}}
+	solo.Behaviour = motion.Priority{avoid(smallOb, bigOb, quarryOb),
+		&motion.Pursue{Target: &d.quarry, MaxPrediction: 2}}
+
+	d.Opponents = []*Steering{small, big, solo}
+}
+
+//SetOpponents sets the spheres and the blue gopher going, or stops them,
+//they start from wherever they are
+func (d *Demo) SetOpponents(on bool) {
+
+	for _, st := range d.Opponents {
+		node := st.Mover.Node()
+		if !on {
+			if d.Tasks.Running(node) == Task(st) {
+				d.Tasks.Cancel(node)
+			}
+			continue
+		}
+		st.Agent.Position = node.Position()
+		st.Agent.Velocity.Zero()
+		d.Tasks.Run(node, st)
+	}
+}
+
+//OpponentsMoving is true while any of the opponents is steering, L and K
+//take the blue gopher back
+func (d *Demo) OpponentsMoving() bool {
+
+	for _, st := range d.Opponents {
+		if d.Tasks.Running(st.Mover.Node()) == Task(st) {
+			return true
+		}
+	}
+	return false
+}
+
+//the opponents see whatever is steered as an agent, its velocity is how far
+//it went this tick whatever the mode's units, N handing over is no jump
+func (d *Demo) updateQuarry(dtime float32) {
+
+	var pos math32.Vector3
+	node := d.Mover.Node()
+	node.WorldPosition(&pos)
+	d.quarry.Velocity.Zero()
+	if dtime > 0 && node == d.quarryNode {
+		d.quarry.Velocity.SubVectors(&pos, &d.quarry.Position).DivideScalar(dtime)
+	}
+	d.quarry.Position, d.quarryNode = pos, node
+}
@@ -0,0 +1,104 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+//U sets the spheres and the blue gopher going: the small sphere patrols, the
+//big one stays about its place and the blue gopher closes in on the green
+//one without running into anything, S stops them and 0 puts them back
+func TestOpponentsMove(t *testing.T) {
+
+	d := NewHeadless()
+	l := NewLoop(d, DefaultRate)
+	start := [3]math32.Vector3{d.Sphere1.Position(), d.Sphere2.Position(), d.SoloGopher.Position()}
+	gopher := d.Gopher.Position()
+	before := d.SoloGopher.Position()
+
+	l.OnKeyDown(KeyEvent{Key: KeyU})
+	for i := 0; i < int(20*DefaultRate); i++ {
+		l.RunTicks(1)
+		s1, s2, solo := d.Sphere1.Position(), d.Sphere2.Position(), d.SoloGopher.Position()
+		if dist := s1.DistanceTo(&s2); dist < 3 {
+			t.Fatalf("tick %d: the spheres are %v apart, they touch", i, dist)
+		}
+		if dist := solo.DistanceTo(&gopher); dist < 1 {
+			t.Fatalf("tick %d: the blue gopher ran into the green one", i)
+		}
+	}
+	if !d.OpponentsMoving() {
+		t.Fatalf("U did not set the opponents going")
+	}
+
+	if s1 := d.Sphere1.Position(); s1.DistanceTo(&start[0]) < 5 {
+		t.Errorf("small sphere at %v, want it off on its patrol", s1)
+	}
+	if s2 := d.Sphere2.Position(); s2.DistanceTo(&bigHome) > wanderRange+2 {
+		t.Errorf("big sphere wandered off to %v", s2)
+	}
+	solo := d.SoloGopher.Position()
+	if solo.DistanceTo(&gopher) > 4 || solo.DistanceTo(&gopher) >= before.DistanceTo(&gopher) {
+		t.Errorf("blue gopher at %v, want her close to the green one", solo)
+	}
+
+	l.OnKeyDown(KeyEvent{Key: KeyS})
+	l.RunTicks(1)
+	stopped := d.Sphere1.Position()
+	l.RunTicks(60)
+	if p := d.Sphere1.Position(); d.OpponentsMoving() || !p.Equals(&stopped) {
+		t.Errorf("S did not stop the opponents")
+	}
+	l.OnKeyDown(KeyEvent{Key: KeyO})
+	l.RunTicks(1)
+	for i, n := range []interface{ Position() math32.Vector3 }{d.Sphere1, d.Sphere2, d.SoloGopher} {
+		if p := n.Position(); !p.Equals(&start[i]) {
+			t.Errorf("after 0 opponent %d at %v, want back at %v", i, p, start[i])
+		}
+	}
+}
+
+//M starts the next mode from the start places, the opponents with it, and
+//they keep going
+func TestOpponentsKeepGoingAcrossModes(t *testing.T) {
+
+	d := NewHeadless()
+	l := NewLoop(d, DefaultRate)
+	start := d.Sphere1.Position()
+
+	l.OnKeyDown(KeyEvent{Key: KeyU})
+	l.RunTicks(60)
+	l.OnKeyDown(KeyEvent{Key: KeyM})
+	l.RunTicks(1)
+	if !d.OpponentsMoving() || d.Mode() != Fly {
+		t.Fatalf("M stopped the opponents, or is in mode %s", ModeName(d.Mode()))
+	}
+	l.RunTicks(60)
+	if p := d.Sphere1.Position(); p.Equals(&start) {
+		t.Errorf("small sphere still at its start %v after M", p)
+	}
+}
+
+//the big sphere runs from whatever is steered once it comes close
+func TestOpponentsEvade(t *testing.T) {
+
+	d := NewHeadless()
+	l := NewLoop(d, DefaultRate)
+	d.Gopher.SetPosition(0, 4, 4)
+	l.OnKeyDown(KeyEvent{Key: KeyU})
+	l.RunTicks(1)
+	d.Tasks.Cancel(d.SoloGopher)
+
+	//fly the gopher at it, it must keep its distance
+	for i := 0; i < int(3*DefaultRate); i++ {
+		p := d.Gopher.Position()
+		p.Z += 2 / DefaultRate
+		d.Gopher.SetPositionVec(&p)
+		l.RunTicks(1)
+	}
+	g, s2 := d.Gopher.Position(), d.Sphere2.Position()
+	if dist := g.DistanceTo(&s2); dist < 4 {
+		t.Errorf("the big sphere let the gopher get within %v", dist)
+	}
+}
@@ -0,0 +1,110 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/math32"
+)
+
+//NodeSwitch tells how N hands control between the green gopher and the
+//camera: the camera blends from where it is to the viewpoint of the node
+//about to be steered in Duration seconds, eased by Ease (nil is linear),
+//then control changes hands. 0 switches at once and leaves the camera be.
+type NodeSwitch struct {
+	Duration float32
+	Ease     motion.Easing
+}
+
+//switchTask is the camera blend of a node switch, a Task on the camera node
+type switchTask struct {
+	d        *Demo
+	to       Mover
+	tween    Tween
+	fromPos  math32.Vector3
+	fromQuat math32.Quaternion
+}
+
+//Update blends the camera one tick, toward a viewpoint that moves along with
+//the gopher, and hands control over once it is there
+func (st *switchTask) Update(dtime float32) bool {
+
+	if !st.tween.Update(dtime) {
+		return false
+	}
+	st.d.handOver(st.to)
+	return true
+}
+
+//blend puts the camera t of the way from where it started to the viewpoint
+func (st *switchTask) blend(t float32) {
+
+	pos, quat := st.d.viewpoint(st.to)
+	p, q := st.fromPos, st.fromQuat
+	p.Lerp(&pos, t)
+	q.Slerp(&quat, t)
+	st.d.Camera.SetPositionVec(&p)
+	st.d.Camera.SetQuaternionQuat(&q)
+}
+
+//toggleNode starts switching between the green gopher and the camera, N
+//again before the blend is done calls it off
+func (d *Demo) toggleNode() {
+
+	if d.Switching() {
+		d.Tasks.Cancel(d.Camera)
+		return
+	}
+
+	to := d.gopherMover
+	if d.Mover.Mover() == d.gopherMover {
+		to = d.cameraMover
+	}
+	if d.Switch.Duration <= 0 {
+		d.handOver(to)
+		return
+	}
+
+	st := &switchTask{d: d, to: to, fromPos: d.Camera.Position(), fromQuat: d.Camera.Quaternion()}
+	st.tween = Tween{Duration: d.Switch.Duration, Ease: d.Switch.Ease, Apply: st.blend}
+	d.switching = st
+	d.Tasks.Run(d.Camera, st)
+}
+
+//Switching is true while the camera blends over to the next node, N has not
+//handed over control yet
+func (d *Demo) Switching() bool {
+	return d.switching != nil && d.Tasks.Running(d.Camera) == Task(d.switching)
+}
+
+//handOver gives control to the gopher or the camera, the camera carries the
+//ship's prow while it is flown
+func (d *Demo) handOver(to Mover) {
+
+	//thrust and turns are along each mover's own axes, so the motion carries over as is
+	if to == d.cameraMover {
+		d.Camera.Add(d.Ship)
+	} else {
+		d.Camera.Remove(d.Ship)
+	}
+	d.Mover.SetMover(to)
+	//the chase camera starts over from where the blend left the camera
+	d.Chase.Reset()
+}
+
+//viewpoint returns where the camera blends to when control goes to the mover:
+//to steer the camera it takes over the gopher's own view, to steer the
+//gopher it looks at her from where the chase camera would be
+func (d *Demo) viewpoint(to Mover) (math32.Vector3, math32.Quaternion) {
+
+	var pos math32.Vector3
+	d.Gopher.WorldPosition(&pos)
+	var q math32.Quaternion
+	d.Gopher.WorldQuaternion(&q)
+	basis := d.gopherMover.Axes().Basis(&q)
+
+	if to == d.cameraMover {
+		return pos, d.cameraMover.Axes().FromBasis(&basis)
+	}
+
+	eye := d.Chase.arm(&pos, &basis)
+	return eye, d.cameraMover.Axes().LookAt(&eye, &pos, &worldUp)
+}
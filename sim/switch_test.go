@@ -0,0 +1,76 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+//N keeps steering the gopher while the camera blends in behind her, and hands
+//over at the end of the blend, with the camera where it was heading
+func TestSwitchBlends(t *testing.T) {
+
+	d := NewHeadless()
+	l := NewLoop(d, DefaultRate)
+	ticks := int(d.Switch.Duration * DefaultRate)
+
+	//to the camera, the camera ends up taking over the gopher's view
+	l.OnKeyDown(KeyEvent{Key: KeyN})
+	l.RunTicks(ticks / 2)
+	if !d.Switching() || d.Current() != d.Gopher {
+		t.Fatalf("half way through steering %q, want still the gopher", d.Current().Name())
+	}
+	l.RunTicks(ticks)
+	if d.Switching() || d.Current() != d.Camera {
+		t.Fatalf("after the blend steering %q, want the camera", d.Current().Name())
+	}
+	if p := d.Camera.Position(); p.Length() > 1e-4 {
+		t.Errorf("camera at %v, want at the gopher", p)
+	}
+	var q math32.Quaternion
+	d.Camera.WorldQuaternion(&q)
+	if b := d.cameraMover.Axes().Basis(&q); b.Forward.Z < 0.999 {
+		t.Errorf("camera looks along %v, want the gopher's +Z", b.Forward)
+	}
+
+	//and back to behind the gopher, looking at her
+	l.OnKeyDown(KeyEvent{Key: KeyN})
+	l.RunTicks(ticks + 1)
+	if d.Current() != d.Gopher {
+		t.Fatalf("after the blend steering %q, want the gopher", d.Current().Name())
+	}
+	if p := d.Camera.Position(); p.DistanceTo(&chaseOffset) > 1e-4 {
+		t.Errorf("camera at %v, want behind the gopher at %v", p, chaseOffset)
+	}
+}
+
+//N again calls the blend off, S does too, and a Duration of 0 switches at
+//once without moving the camera
+func TestSwitchCancelAndInstant(t *testing.T) {
+
+	d := NewHeadless()
+	l := NewLoop(d, DefaultRate)
+	ticks := int(d.Switch.Duration * DefaultRate)
+
+	for _, key := range []Key{KeyN, KeyS} {
+		l.OnKeyDown(KeyEvent{Key: KeyN})
+		l.RunTicks(ticks / 2)
+		l.OnKeyDown(KeyEvent{Key: key})
+		l.RunTicks(ticks)
+		if d.Switching() || d.Current() != d.Gopher {
+			t.Errorf("%v did not call the switch off, steering %q", key, d.Current().Name())
+		}
+	}
+
+	d.Reset()
+	d.Switch.Duration = 0
+	before := d.Camera.Position()
+	l.OnKeyDown(KeyEvent{Key: KeyN})
+	l.RunTicks(1)
+	if d.Current() != d.Camera {
+		t.Errorf("steering %q, want the camera at once", d.Current().Name())
+	}
+	if p := d.Camera.Position(); !p.Equals(&before) {
+		t.Errorf("camera moved from %v to %v", before, p)
+	}
+}
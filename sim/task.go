@@ -0,0 +1,178 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//Task is a timed behaviour run by a Scheduler, Update is called once per
+//tick with the tick's time step and returns true when the task is done
+type Task interface {
+	Update(dtime float32) bool
+}
+
+//TaskFunc makes a plain function a Task
+type TaskFunc func(dtime float32) bool
+
+//Update calls f
+func (f TaskFunc) Update(dtime float32) bool {
+	return f(dtime)
+}
+
+//Tween runs for Duration seconds of simulated time and calls Apply with the
+//fraction done, eased by Ease (nil is linear), the last call is always with 1
+type Tween struct {
+	Duration float32
+	Ease     motion.Easing
+	Apply    func(t float32)
+	elapsed  float32
+}
+
+//Update advances the tween by dtime
+func (tw *Tween) Update(dtime float32) bool {
+
+	tw.elapsed += dtime
+	if tw.elapsed >= tw.Duration {
+		tw.Apply(1)
+		return true
+	}
+	t := tw.elapsed / tw.Duration
+	if tw.Ease != nil {
+		t = tw.Ease(t)
+	}
+	tw.Apply(t)
+	return false
+}
+
+//SlerpSpec tells how a slerp turns: in Duration seconds, or, when Speed is
+//set, at no more than Speed radians per second, either way eased by Ease
+//(nil is linear)
+type SlerpSpec struct {
+	Duration, Speed float32
+	Ease            motion.Easing
+}
+
+//Slerp returns a tween turning node from where it is now to the rotation to
+//in duration seconds, at a steady angular speed
+func Slerp(node *core.Node, to *math32.Quaternion, duration float32) *Tween {
+	return SlerpBy(node, to, SlerpSpec{Duration: duration})
+}
+
+//SlerpBy returns a tween turning node from where it is now to the rotation
+//to the way spec says
+func SlerpBy(node *core.Node, to *math32.Quaternion, spec SlerpSpec) *Tween {
+
+	from := node.Quaternion()
+	from.Normalize()
+	target := *to
+	target.Normalize()
+
+	duration := spec.Duration
+	if spec.Speed > 0 {
+		//an eased turn is faster than average somewhere, keep that part under Speed
+		slope := float32(1)
+		if spec.Ease != nil {
+			slope = motion.MaxSlope(spec.Ease)
+		}
+		duration = motion.QuatAngle(&from, &target) * slope / spec.Speed
+	}
+
+	var q math32.Quaternion
+	return &Tween{Duration: duration, Ease: spec.Ease, Apply: func(t float32) {
+		//Slerp() changes the quat it is called on, always start over from the
+		//start rotation so t is the fraction of the whole turn, an elastic
+		//t past 1 turns on past the target and back
+		q = from
+		node.SetQuaternionQuat(q.Slerp(&target, t))
+	}}
+}
+
+//scheduled is a task and the node it works on, id tells a task from the one
+//that replaced it, tasks themselves need not be comparable (TaskFunc)
+type scheduled struct {
+	node *core.Node
+	task Task
+	id   uint64
+}
+
+//Scheduler runs tasks on the simulation tick, the same thread and the same
+//time as all other movement, so tasks never race the renderer and replay
+//like the rest. A node has at most one task, a new one replaces the old.
+type Scheduler struct {
+	tasks   []scheduled
+	ticking []scheduled
+	lastID  uint64
+}
+
+//Run starts a task on a node, cancelling the node's current task if any
+func (s *Scheduler) Run(node *core.Node, t Task) {
+
+	s.lastID++
+	if i := s.find(node); i >= 0 {
+		s.tasks[i].task, s.tasks[i].id = t, s.lastID
+		return
+	}
+	s.tasks = append(s.tasks, scheduled{node: node, task: t, id: s.lastID})
+}
+
+//Cancel stops the task of a node, false if it had none
+func (s *Scheduler) Cancel(node *core.Node) bool {
+
+	i := s.find(node)
+	if i < 0 {
+		return false
+	}
+	s.tasks = append(s.tasks[:i], s.tasks[i+1:]...)
+	return true
+}
+
+//CancelAll stops every task
+func (s *Scheduler) CancelAll() {
+	s.tasks = s.tasks[:0]
+}
+
+//Running returns the task of a node, nil if it has none
+func (s *Scheduler) Running(node *core.Node) Task {
+
+	if i := s.find(node); i >= 0 {
+		return s.tasks[i].task
+	}
+	return nil
+}
+
+//Len returns the number of tasks running
+func (s *Scheduler) Len() int {
+	return len(s.tasks)
+}
+
+//Update runs every task one tick, in the order they were started. A task may
+//start or cancel tasks, a task started during the tick runs from the next one.
+func (s *Scheduler) Update(dtime float32) {
+
+	s.ticking = append(s.ticking[:0], s.tasks...)
+	for _, sc := range s.ticking {
+		//cancelled or replaced by a task that ran before it
+		if !s.current(sc) {
+			continue
+		}
+		if sc.task.Update(dtime) && s.current(sc) {
+			s.Cancel(sc.node)
+		}
+	}
+}
+
+//is sc still the task of its node
+func (s *Scheduler) current(sc scheduled) bool {
+	i := s.find(sc.node)
+	return i >= 0 && s.tasks[i].id == sc.id
+}
+
+func (s *Scheduler) find(node *core.Node) int {
+	for i := range s.tasks {
+		if s.tasks[i].node == node {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,128 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//a tween ends after its duration in simulated time, whatever the tick size
+func TestTweenDuration(t *testing.T) {
+
+	for _, dtime := range []float32{1.0 / 30, 1.0 / 60, 1.0 / 144} {
+		var s Scheduler
+		node := core.NewNode()
+		var last float32
+		ticks := 0
+		s.Run(node, &Tween{Duration: 0.5, Apply: func(f float32) { last = f }})
+		for s.Len() > 0 {
+			s.Update(dtime)
+			ticks++
+		}
+		if last != 1 {
+			t.Errorf("dtime %v: last fraction %v, want 1", dtime, last)
+		}
+		if done := float32(ticks) * dtime; done < 0.5-dtime || done > 0.5+dtime {
+			t.Errorf("dtime %v: done after %v s, want 0.5", dtime, done)
+		}
+	}
+}
+
+//a node runs one task at a time, a new one replaces the one in flight
+func TestSchedulerReplaceCancel(t *testing.T) {
+
+	var s Scheduler
+	a, b := core.NewNode(), core.NewNode()
+	var ranFirst, ranSecond, ranOther int
+	s.Run(a, TaskFunc(func(float32) bool { ranFirst++; return false }))
+	s.Run(b, TaskFunc(func(float32) bool { ranOther++; return ranOther == 3 }))
+	s.Update(0.1)
+
+	s.Run(a, TaskFunc(func(float32) bool { ranSecond++; return false }))
+	for i := 0; i < 4; i++ {
+		s.Update(0.1)
+	}
+	if ranFirst != 1 || ranSecond != 4 {
+		t.Errorf("replaced task ran %d times, replacement %d, want 1 and 4", ranFirst, ranSecond)
+	}
+	if ranOther != 3 || s.Running(b) != nil {
+		t.Errorf("finished task ran %d times, want 3 and then be gone", ranOther)
+	}
+
+	if !s.Cancel(a) || s.Cancel(a) || s.Len() != 0 {
+		t.Errorf("cancel did not remove the task, %d left", s.Len())
+	}
+	s.Update(0.1)
+	if ranSecond != 4 {
+		t.Errorf("cancelled task still ran")
+	}
+}
+
+//the slerp ends exactly at its target and turns steadily on the way
+func TestSlerpTask(t *testing.T) {
+
+	node := core.NewNode()
+	var to math32.Quaternion
+	to.SetFromEuler(math32.NewVector3(0, 2, 0))
+
+	var s Scheduler
+	s.Run(node, Slerp(node, &to, 1))
+	var prev math32.Quaternion
+	prev.SetIdentity()
+	var steps []float32
+	//2 rad about Y in 8 ticks
+	for s.Len() > 0 {
+		s.Update(0.125)
+		q := node.Quaternion()
+		steps = append(steps, motion.QuatAngle(&prev, &q))
+		prev = q
+	}
+
+	got := node.Quaternion()
+	if a := motion.QuatAngle(&got, &to); a > 1e-3 {
+		t.Errorf("slerp ended at %v, want %v", got, to)
+	}
+	for i, step := range steps {
+		if math32.Abs(step-0.25) > 1e-3 {
+			t.Errorf("step %d turned %v rad, want a steady 0.25", i, step)
+		}
+	}
+}
+
+//by speed the turn takes as long as the fastest part of the easing allows
+func TestSlerpBySpeed(t *testing.T) {
+
+	var to math32.Quaternion
+	to.SetFromEuler(math32.NewVector3(0, 2, 0))
+
+	tests := []struct {
+		spec SlerpSpec
+		want float32
+	}{
+		{SlerpSpec{Duration: 3}, 3},
+		{SlerpSpec{Duration: 3, Speed: 1}, 2},
+		{SlerpSpec{Speed: 1, Ease: motion.EaseInOut}, 4},
+	}
+
+	for _, tt := range tests {
+		node := core.NewNode()
+		tw := SlerpBy(node, &to, tt.spec)
+		if math32.Abs(tw.Duration-tt.want) > 0.02 {
+			t.Errorf("%+v: duration %v, want %v", tt.spec, tw.Duration, tt.want)
+		}
+
+		//and never turns faster than asked
+		if tt.spec.Speed > 0 {
+			prev := node.Quaternion()
+			for !tw.Update(0.01) {
+				q := node.Quaternion()
+				if step := motion.QuatAngle(&prev, &q); step > tt.spec.Speed*0.01*1.01 {
+					t.Errorf("%+v: turned %v rad in one 0.01 s tick", tt.spec, step)
+				}
+				prev = q
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/math32"
+)
+
+//Tracker is a Task that keeps turning a mover to face a target that may be
+//moving, never faster than Speed radians per second. It runs until it is
+//cancelled, run it on the mover's node with a Scheduler.
+type Tracker struct {
+	Mover Mover
+	//Target puts the world position to face in its argument, every tick
+	Target func(pos *math32.Vector3)
+	//most radians per second it turns
+	Speed float32
+	//YawOnly turns about world up only, the mover stays level
+	YawOnly bool
+	//PitchLimit is how far above or below the horizon it may look, in
+	//radians, 0 or a right angle and more is no limit
+	PitchLimit float32
+
+	eye, target, dir math32.Vector3
+}
+
+//Update turns the mover one tick toward the target, it never finishes
+func (tr *Tracker) Update(dtime float32) bool {
+
+	node := tr.Mover.Node()
+	tr.Target(&tr.target)
+	node.WorldPosition(&tr.eye)
+	tr.constrain()
+
+	look := LookAt(tr.Mover, &tr.target)
+	cur := node.Quaternion()
+	look = motion.ApproachQuat(&look, &cur, tr.Speed*dtime)
+	node.SetQuaternionQuat(&look)
+	return false
+}
+
+//move the target so facing it keeps within YawOnly and PitchLimit
+func (tr *Tracker) constrain() {
+
+	if !tr.YawOnly && (tr.PitchLimit <= 0 || tr.PitchLimit >= math32.Pi/2) {
+		return
+	}
+
+	//world up is Y, so the height of the target over the eye is dir.Y
+	tr.dir.SubVectors(&tr.target, &tr.eye)
+	up := tr.dir.Y
+	level := math32.Sqrt(tr.dir.X*tr.dir.X + tr.dir.Z*tr.dir.Z)
+	if level < 1e-6 {
+		//straight above or below, no heading to go by, stay as we are
+		tr.target = tr.eye
+		return
+	}
+
+	max := level * math32.Tan(tr.PitchLimit)
+	switch {
+	case tr.YawOnly:
+		up = 0
+	case up > max:
+		up = max
+	case up < -max:
+		up = -max
+	}
+
+	tr.dir.Y = up
+	tr.target.AddVectors(&tr.eye, &tr.dir)
+}
@@ -0,0 +1,122 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//a tracker must catch up with a moving target, never turning faster than allowed
+func TestTrackerFollowsMovingTarget(t *testing.T) {
+
+	node := core.NewNode()
+	node.SetPosition(1, 0, -2)
+	target := math32.NewVector3(10, 2, 0)
+	tr := &Tracker{Mover: ObjectMover(node), Speed: 1.5,
+		Target: func(pos *math32.Vector3) { *pos = *target }}
+
+	var s Scheduler
+	s.Run(node, tr)
+	const dtime = 1.0 / 60
+	for i := 0; i < 600; i++ {
+		//circle the node, half as fast as the tracker can turn
+		a := float32(i) * dtime * tr.Speed / 2
+		target.Set(1+10*math32.Cos(a), 2, -2+10*math32.Sin(a))
+
+		before := node.Quaternion()
+		s.Update(dtime)
+		after := node.Quaternion()
+		if step := motion.QuatAngle(&before, &after); step > tr.Speed*dtime*1.01 {
+			t.Fatalf("tick %d: turned %v rad, allowed %v", i, step, tr.Speed*dtime)
+		}
+	}
+
+	var eye math32.Vector3
+	node.WorldPosition(&eye)
+	want := *target
+	want.Sub(&eye).Normalize()
+	if b := NewController(tr.Mover).Basis(); b.Forward.Dot(&want) < 0.999 {
+		t.Errorf("forward %v, want about %v", b.Forward, want)
+	}
+	if s.Len() != 1 {
+		t.Errorf("tracker finished, it should run until cancelled")
+	}
+}
+
+func TestTrackerConstraints(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		yawOnly  bool
+		limit    float32
+		maxPitch float32
+	}{
+		{"yaw only", true, 0, 0},
+		{"pitch limit", false, 0.3, 0.3},
+		{"no limit", false, 0, math32.Pi / 2},
+		{"past a right angle", false, 2, math32.Pi / 2},
+	}
+
+	//well above, then well below
+	for _, tt := range tests {
+		for _, y := range []float32{30, -30} {
+			node := core.NewNode()
+			tr := &Tracker{Mover: ObjectMover(node), Speed: 100, YawOnly: tt.yawOnly, PitchLimit: tt.limit,
+				Target: func(pos *math32.Vector3) { pos.Set(5, y, 5) }}
+			tr.Update(1)
+
+			b := NewController(tr.Mover).Basis()
+			pitch := math32.Asin(b.Forward.Y)
+			if math32.Abs(pitch) > tt.maxPitch+1e-3 {
+				t.Errorf("%s, target at y %v: pitched %v, limit %v", tt.name, y, pitch, tt.maxPitch)
+			}
+			if pitch*y < 0 {
+				t.Errorf("%s, target at y %v: pitched %v, away from it", tt.name, y, pitch)
+			}
+			if tt.limit == tt.maxPitch && tt.limit > 0 && math32.Abs(math32.Abs(pitch)-tt.limit) > 1e-3 {
+				t.Errorf("%s, target at y %v: pitched %v, want held at the limit %v", tt.name, y, pitch, tt.limit)
+			}
+			//always turned toward it, and never rolled
+			if b.Forward.X <= 0 || b.Forward.Z <= 0 || !near(b.Right.Y, 0) {
+				t.Errorf("%s, target at y %v: basis %v", tt.name, y, b)
+			}
+		}
+	}
+}
+
+//K tracks whichever target L picked, L keeps cycling them
+func TestDemoTracking(t *testing.T) {
+
+	d := NewHeadless()
+	l := NewLoop(d, DefaultRate)
+	k, _ := ParseKey("k")
+	keys, _ := ParseKeys("m,z,z,z,y")
+	for _, kev := range append(keys, k) {
+		l.OnKeyDown(kev)
+	}
+	l.OnKeyDown(KeyEvent{Key: KeyL})
+	l.OnKeyDown(KeyEvent{Key: KeyL})
+	l.RunTicks(1)
+	if !d.Tracking() || d.ToggleLookAtTarget%3 != 1 {
+		t.Fatalf("not tracking the large sphere")
+	}
+	l.OnKeyDown(KeyEvent{Key: KeyL})
+	l.RunTicks(int(5 * DefaultRate))
+
+	//the green gopher flew off, she kept looking at him
+	var eye, at math32.Vector3
+	d.SoloGopher.WorldPosition(&eye)
+	d.Gopher.WorldPosition(&at)
+	want := *at.Sub(&eye).Normalize()
+	if b := NewController(d.soloMover).Basis(); b.Forward.Dot(&want) < 0.999 {
+		t.Errorf("forward %v, want about %v", b.Forward, want)
+	}
+
+	l.OnKeyDown(k)
+	l.RunTicks(1)
+	if d.Tracking() {
+		t.Errorf("K did not stop tracking")
+	}
+}
@@ -0,0 +1,154 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/math32"
+)
+
+//Handling tunes vehicle mode, see Controller.SetHandling. It is a bicycle
+//model: the front wheel steers, the rear one follows, so the turn is as
+//tight as the steering and as quick as the speed.
+type Handling struct {
+	//Wheelbase is how far the front wheel is from the rear one, MaxSteer
+	//how far the front wheel turns, in radians, and SteerRate how fast
+	Wheelbase, MaxSteer, SteerRate float32
+	//Engine, Brake and Reverse are the accelerations of the pedals, full
+	//down, in units per second squared, PedalRate how fast the pedal goes
+	//down or comes back, per second
+	Engine, Brake, Reverse float32
+	PedalRate              float32
+	//Drag slows it by this much per unit of speed, Engine/Drag is its top speed
+	Drag float32
+	//Grip is how much sideways sliding the tyres stop, HandbrakeGrip how
+	//much with the handbrake on, in units per second squared, and
+	//HandbrakeBrake how hard the handbrake slows it
+	Grip, HandbrakeGrip, HandbrakeBrake float32
+}
+
+//SetHandling changes how vehicle mode drives
+func (c *Controller) SetHandling(h Handling) {
+	c.handling = h
+}
+
+//Handling returns how vehicle mode drives
+func (c *Controller) Handling() Handling {
+	return c.handling
+}
+
+//Steering returns vehicle mode's steering angle, in radians, positive to the left
+func (c *Controller) Steering() float32 {
+	return c.steer
+}
+
+//Slip returns how fast vehicle mode slides sideways, positive to the right
+func (c *Controller) Slip() float32 {
+	b := c.Basis()
+	fwd := heading(&b)
+	var right math32.Vector3
+	right.CrossVectors(&fwd, &worldUp)
+	return c.vecVelocity.Dot(&right)
+}
+
+//this sets the pedals and the steering in vehicle mode, called from
+//OnAction. They are held keys by default, pressed they set a steady
+//pedal or steering, and pressed the handbrake goes on and off.
+func (c *Controller) Vehicle(a Action) bool {
+
+	switch a {
+
+	case ActThrottle:
+		c.vecMovementGoal.Z = math32.Min(c.vecMovementGoal.Z+pedalStep, 1)
+
+	case ActBrake:
+		c.vecMovementGoal.Z = math32.Max(c.vecMovementGoal.Z-pedalStep, -1)
+
+	case ActSteerLeft:
+		c.vecRotationGoal.Y = math32.Min(c.vecRotationGoal.Y+pedalStep, 1)
+
+	case ActSteerRight:
+		c.vecRotationGoal.Y = math32.Max(c.vecRotationGoal.Y-pedalStep, -1)
+
+	case ActHandbrake:
+		c.handbrake = !c.handbrake
+
+	default:
+		return false
+	}
+	return true
+}
+
+//vehicle mode, called from Update: the pedals push it along its heading, the
+//steering turns it in proportion to its speed and the tyres' grip stops it
+//sliding sideways, unless the handbrake is on. It stays on the grid plane.
+func (c *Controller) updateVehicle(dtime float32) {
+
+	if c.frozen {
+		return
+	}
+	node := c.mover.Node()
+	hd := &c.handling
+
+	//held keys press the pedal and turn the wheel, pressed keys set them,
+	//the pedal eases down and back like Approach eases fly mode's thrust
+	pedal, steer := c.vecMovementGoal.Z, c.vecRotationGoal.Y
+	if c.moveOn[2] {
+		pedal = c.moveInput.Z
+	}
+	if c.rotOn[1] {
+		steer = c.rotInput.Y
+	}
+	c.vecMovement.Z = motion.Approach(pedal, c.vecMovement.Z, hd.PedalRate*dtime)
+	c.steer = motion.Approach(steer*hd.MaxSteer, c.steer, hd.SteerRate*dtime)
+	handbrake := c.handbrake
+	if c.moveOn[1] {
+		handbrake = c.moveInput.Y > 0
+	}
+
+	node.WorldQuaternion(&c.quatView)
+	c.viewBasis = c.mover.Axes().Basis(&c.quatView)
+	fwd := heading(&c.viewBasis)
+	var right math32.Vector3
+	right.CrossVectors(&fwd, &worldUp)
+	along, across := c.vecVelocity.Dot(&fwd), c.vecVelocity.Dot(&right)
+
+	//the brake pedal brakes going forward and reverses once stopped
+	push := c.vecMovement.Z
+	switch {
+	case push > 0:
+		along += push * hd.Engine * dtime
+	case push < 0 && along > stoppedSpeed:
+		along = motion.Approach(0, along, -push*hd.Brake*dtime)
+	case push < 0:
+		along += push * hd.Reverse * dtime
+	}
+	along -= along * hd.Drag * dtime
+
+	grip := hd.Grip
+	if handbrake {
+		grip = hd.HandbrakeGrip
+		along = motion.Approach(0, along, hd.HandbrakeBrake*dtime)
+	}
+	across = motion.Approach(0, across, grip*dtime)
+
+	//the velocity stays put in the world while the body turns, so what the
+	//grip cannot stop of the turn is a slide
+	c.vecVelocity.Set(fwd.X*along+right.X*across, 0, fwd.Z*along+right.Z*across)
+
+	//the rear wheel follows the front one, yaw rate = speed tan(steer) / wheelbase,
+	//sliding the front wheel still bites with all the speed there is
+	if hd.Wheelbase > 0 {
+		speed := c.vecVelocity.Length()
+		if along < 0 {
+			speed = -speed
+		}
+		c.vecRotation.Y = speed * math32.Tan(c.steer) / hd.Wheelbase
+		c.turnWorld(&worldUp, c.vecRotation.Y*dtime)
+	}
+
+	c.vecStep = c.vecVelocity
+	c.vecStep.MultiplyScalar(dtime)
+	c.usePos = node.Position()
+	c.usePos.Add(&c.vecStep)
+	c.usePos.Y = 0
+	node.SetPositionVec(&c.usePos)
+}
@@ -0,0 +1,97 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//the throttle eases in to its top speed, the brake pedal stops it before it
+//reverses, all on the grid plane
+func TestVehicleDrivesBrakesReverses(t *testing.T) {
+
+	c := newInMode(Vehicle, ObjectMover(core.NewNode()))
+	c.OnKeyDown(KeyEvent{Key: KeyZ})
+	c.ticks(1)
+	if p := c.vecMovement.Z; p <= 0 || p >= 0.1 {
+		t.Errorf("the pedal went to %v in a tick, want it easing down", p)
+	}
+	c.ticks(599)
+	c.OnKeyUp(KeyEvent{Key: KeyZ})
+	h := c.Handling()
+	if v := c.Velocity(); math32.Abs(v.Z-h.Engine/h.Drag) > 1.5 || v.X != 0 || c.Node().Position().Y != 0 {
+		t.Errorf("drove at %v, want about %v along +Z", v, h.Engine/h.Drag)
+	}
+
+	c.OnKeyDown(KeyEvent{Key: KeyZ, Mods: ModControl})
+	stopped := false
+	last := c.Velocity().Z
+	for i := 0; i < 300; i++ {
+		c.ticks(1)
+		v := c.Velocity().Z
+		if c.vecMovement.Z <= 0 && v > last+1e-4 {
+			t.Fatalf("the brake sped it up from %v to %v", last, v)
+		}
+		stopped = stopped || math32.Abs(v) <= stoppedSpeed
+		if v < -stoppedSpeed && !stopped {
+			t.Fatalf("reversed at %v without stopping", v)
+		}
+		last = v
+	}
+	if last > -2 {
+		t.Errorf("reversing at %v, want going backward", last)
+	}
+}
+
+//the turn rate is speed tan(steer) / wheelbase, quicker the faster it goes
+func TestVehicleSteersWithSpeed(t *testing.T) {
+
+	var rates [2]float32
+	for i, speed := range []float32{2, 4} {
+		c := newInMode(Vehicle, ObjectMover(core.NewNode()))
+		c.vecVelocity.Set(0, 0, speed)
+		c.OnKeyDown(KeyEvent{Key: KeyY})
+		c.ticks(30)
+		h := c.Handling()
+		if c.Steering() != h.MaxSteer {
+			t.Errorf("steered to %v, want full lock %v", c.Steering(), h.MaxSteer)
+		}
+		v := c.Velocity()
+		want := v.Length() * math32.Tan(c.Steering()) / h.Wheelbase
+		if rates[i] = c.vecRotation.Y; math32.Abs(rates[i]-want) > 1e-3 {
+			t.Errorf("speed %v: turning at %v, want %v", speed, rates[i], want)
+		}
+		if slip := c.Slip(); math32.Abs(slip) > 0.5 {
+			t.Errorf("speed %v: slipping %v", speed, slip)
+		}
+	}
+	if rates[1] < 1.5*rates[0] {
+		t.Errorf("faster it turned at %v, slower at %v", rates[1], rates[0])
+	}
+}
+
+//the handbrake lets go of the grip and the car drifts sideways through the turn
+func TestHandbrakeDrift(t *testing.T) {
+
+	for _, handbrake := range []bool{false, true} {
+		c := newInMode(Vehicle, ObjectMover(core.NewNode()))
+		c.vecVelocity.Set(0, 0, 8)
+		//half lock, a steady turn the tyres hold
+		c.bindings.SetContinuous(ModeName(Vehicle), ActSteerLeft, false)
+		c.bindings.SetContinuous(ModeName(Vehicle), ActSteerRight, false)
+		c.OnAction(ActSteerLeft)
+		c.OnAction(ActSteerLeft)
+		if handbrake {
+			c.OnKeyDown(KeyEvent{Key: KeySpace})
+		}
+		slip := float32(0)
+		for i := 0; i < 60; i++ {
+			c.ticks(1)
+			slip = math32.Max(slip, math32.Abs(c.Slip()))
+		}
+		if handbrake && slip < 2 || !handbrake && slip > 1 {
+			t.Errorf("handbrake %v: slid sideways at up to %v", handbrake, slip)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package sim
+
+import (
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/g3n/engine/math32"
+)
+
+//Walking tunes walk mode, see Controller.SetWalking
+type Walking struct {
+	//Speed is how fast it walks and steps sideways, in units per second
+	Speed float32
+	//EyeHeight is how high above the floor it walks
+	EyeHeight float32
+	//Sensitivity is how many radians it turns per pixel the mouse moves
+	Sensitivity float32
+	//PitchLimit is how far above or below the horizon it may look, in
+	//radians, less than Pi/2
+	PitchLimit float32
+	//Bob is how far the head goes up and down, 0 is no head bob, Stride
+	//is how far one step takes it
+	Bob, Stride float32
+}
+
+//SetWalking changes how walk mode walks and looks
+func (c *Controller) SetWalking(w Walking) {
+	c.walking = w
+}
+
+//Walking returns how walk mode walks and looks
+func (c *Controller) Walking() Walking {
+	return c.walking
+}
+
+//OnMouseLook turns walk mode's view by a mouse movement of dx, dy pixels,
+//to the right and down like the screen, other modes ignore it
+func (c *Controller) OnMouseLook(dx, dy float32) {
+
+	if c.mvType != Walk {
+		return
+	}
+	c.startLook()
+	c.lookYaw -= dx * c.walking.Sensitivity
+	c.lookPitch -= dy * c.walking.Sensitivity
+	c.clampPitch()
+}
+
+//this sets the motion vectors that will be used walking, called from OnAction.
+//Walking and looking are held keys by default, pressed they set a steady pace.
+func (c *Controller) Walk(a Action) bool {
+
+	switch a {
+
+	//X is to the right and Z forward, see updateWalk
+	case ActRunForward:
+		c.vecMovementGoal.Z += incrementLinearTranslate
+
+	case ActRunBackward:
+		c.vecMovementGoal.Z -= incrementLinearTranslate
+
+	case ActStrafeLeft:
+		c.vecMovementGoal.X -= incrementLinearTranslate
+
+	case ActStrafeRight:
+		c.vecMovementGoal.X += incrementLinearTranslate
+
+	//Y turns left and X looks up, like the fly pitch and yaw
+	case ActTurnLeft:
+		c.vecRotationGoal.Y += incrementRotTranslate
+
+	case ActTurnRight:
+		c.vecRotationGoal.Y -= incrementRotTranslate
+
+	case ActLookUp:
+		c.vecRotationGoal.X += incrementRotTranslate
+
+	case ActLookDown:
+		c.vecRotationGoal.X -= incrementRotTranslate
+
+	default:
+		return false
+	}
+	return true
+}
+
+//walk mode, called from Update: look with the mouse or the keys, walk and
+//step sideways along the floor at eye height, the head bobbing with the steps
+func (c *Controller) updateWalk(dtime float32) {
+
+	if c.frozen {
+		return
+	}
+	node := c.mover.Node()
+	c.startLook()
+
+	//held keys drive their axes, the key presses set a steady pace
+	for i := 0; i < 3; i++ {
+		move, rot := c.vecMovementGoal.Component(i), c.vecRotationGoal.Component(i)
+		if c.moveOn[i] {
+			move = c.moveInput.Component(i) * c.walking.Speed
+		}
+		if c.rotOn[i] {
+			rot = c.rotInput.Component(i) * turnSpeed
+		}
+		c.vecMovement.SetComponent(i, motion.Approach(move, c.vecMovement.Component(i), runRamp*dtime))
+		c.vecRotation.SetComponent(i, motion.Approach(rot, c.vecRotation.Component(i), turnRamp*dtime))
+	}
+	c.lookYaw += c.vecRotation.Y * dtime
+	c.lookPitch += c.vecRotation.X * dtime
+	c.clampPitch()
+
+	//the heading is the yaw alone, looking up or down does not slow the walk
+	sin, cos := math32.Sin(c.lookYaw), math32.Cos(c.lookYaw)
+	fwd := math32.Vector3{X: sin, Z: cos}
+	var right math32.Vector3
+	right.CrossVectors(&fwd, &worldUp)
+	c.vecVelocity.Set(fwd.X*c.vecMovement.Z+right.X*c.vecMovement.X, 0,
+		fwd.Z*c.vecMovement.Z+right.Z*c.vecMovement.X)
+
+	c.vecStep = c.vecVelocity
+	c.vecStep.MultiplyScalar(dtime)
+	c.usePos = node.Position()
+	c.usePos.Add(&c.vecStep)
+
+	//half a bob per stride, the bob fades in and out with the speed
+	bob := float32(0)
+	if c.walking.Bob > 0 && c.walking.Stride > 0 && c.walking.Speed > 0 {
+		speed := c.vecVelocity.Length()
+		c.bobPhase += speed * dtime / c.walking.Stride * math32.Pi
+		bob = c.walking.Bob * math32.Min(speed/c.walking.Speed, 1) * math32.Abs(math32.Sin(c.bobPhase))
+	}
+	c.usePos.Y = c.walking.EyeHeight + bob
+	node.SetPositionVec(&c.usePos)
+
+	//then look along yaw and pitch from where it now is
+	cosPitch := math32.Cos(c.lookPitch)
+	target := math32.Vector3{X: sin * cosPitch, Y: math32.Sin(c.lookPitch), Z: cos * cosPitch}
+	node.WorldPosition(&c.vecStep)
+	target.Add(&c.vecStep)
+	c.quatView = LookAt(c.mover, &target)
+	node.SetQuaternionQuat(&c.quatView)
+}
+
+//take yaw and pitch from the way the mover faces, once after it starts walking
+func (c *Controller) startLook() {
+
+	if c.looking {
+		return
+	}
+	c.looking = true
+	fwd := c.Basis().Forward
+	c.lookYaw = math32.Atan2(fwd.X, fwd.Z)
+	c.lookPitch = math32.Asin(math32.Clamp(fwd.Y, -1, 1))
+	c.clampPitch()
+}
+
+func (c *Controller) clampPitch() {
+	c.lookPitch = math32.Clamp(c.lookPitch, -c.walking.PitchLimit, c.walking.PitchLimit)
+}
@@ -0,0 +1,129 @@
+package sim
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+//the mouse turns right and looks down with the screen, upright and never
+//further up or down than PitchLimit
+func TestMouseLook(t *testing.T) {
+
+	c := newInMode(Walk, CameraMover(core.NewNode()))
+	c.ticks(1)
+	c.OnMouseLook(100, 0)
+	c.ticks(1)
+	b := c.Basis()
+	if b.Forward.X <= 0 || math32.Abs(b.Forward.Y) > 1e-5 {
+		t.Errorf("mouse right from -Z should turn to +X, forward %v", b.Forward)
+	}
+
+	c.OnMouseLook(0, 1e4)
+	c.ticks(1)
+	b = c.Basis()
+	limit := c.Walking().PitchLimit
+	if pitch := math32.Asin(b.Forward.Y); math32.Abs(pitch+limit) > 1e-3 {
+		t.Errorf("looked down to %v rad, want the limit %v", pitch, -limit)
+	}
+	if math32.Abs(b.Right.Y) > 1e-5 {
+		t.Errorf("rolled, right %v", b.Right)
+	}
+
+	//other modes leave the mouse to the orbit control
+	c.SetMode(Fly)
+	before := c.Node().Quaternion()
+	c.OnMouseLook(100, 100)
+	c.ticks(1)
+	if after := c.Node().Quaternion(); !after.Equals(&before) {
+		t.Errorf("fly mode turned with the mouse")
+	}
+}
+
+//walking goes along the heading at eye height whatever the pitch, the head
+//bobbing no more than Bob and settling when it stops
+func TestWalkOnTheGround(t *testing.T) {
+
+	for _, bob := range []float32{0, 0.1} {
+		c := newInMode(Walk, CameraMover(core.NewNode()))
+		w := c.Walking()
+		w.Bob = bob
+		c.SetWalking(w)
+		c.ticks(1)
+		c.OnMouseLook(-200, -150)
+		c.ticks(1)
+		heading := c.Basis().Forward
+		heading.Y = 0
+		heading.Normalize()
+		start := c.Node().Position()
+
+		c.OnKeyDown(KeyEvent{Key: KeyZ})
+		low, high := float32(100), float32(-100)
+		for i := 0; i < 90; i++ {
+			c.ticks(1)
+			y := c.Node().Position().Y
+			low, high = math32.Min(low, y), math32.Max(high, y)
+		}
+		c.OnKeyUp(KeyEvent{Key: KeyZ})
+		c.ticks(30)
+
+		moved := c.Node().Position()
+		moved.Sub(&start)
+		moved.Y = 0
+		if moved.Length() < 2 || moved.Normalize().Dot(&heading) < 0.999 {
+			t.Errorf("bob %v: walked %v, want ahead along %v", bob, moved, heading)
+		}
+		if low < w.EyeHeight || high > w.EyeHeight+bob+1e-5 || bob > 0 && high-low < bob/2 {
+			t.Errorf("bob %v: eye went from %v to %v, eye height %v", bob, low, high, w.EyeHeight)
+		}
+		if y := c.Node().Position().Y; y != w.EyeHeight {
+			t.Errorf("bob %v: stopped with the eye at %v, want %v", bob, y, w.EyeHeight)
+		}
+	}
+}
+
+//M into walk mode hands the camera over, and mouse looks are recorded and
+//replayed like keys
+func TestWalkReplay(t *testing.T) {
+
+	d := NewHeadless()
+	l := NewLoop(d, DefaultRate)
+	l.Record()
+	for i := 0; i < 3; i++ {
+		l.OnKeyDown(KeyEvent{Key: KeyM})
+		l.RunTicks(1)
+	}
+	if d.Mode() != Walk || d.Current() != d.Camera {
+		t.Fatalf("mode %v steering %q, want walk mode on the camera", ModeName(d.Mode()), d.Current().Name())
+	}
+
+	l.OnKeyDown(KeyEvent{Key: KeyZ})
+	for i := 0; i < 60; i++ {
+		l.OnMouseLook(1.5, 0.25)
+		l.OnMouseLook(0.5, -0.5)
+		l.RunTicks(1)
+	}
+	l.OnKeyUp(KeyEvent{Key: KeyZ})
+	l.RunTicks(20)
+	want := d.States()
+
+	var buf bytes.Buffer
+	if err := l.Recording().Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	r, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Events) != 3+1+60+1 {
+		t.Errorf("recorded %d events, want the looks of a tick added up", len(r.Events))
+	}
+	got := PlayHeadless(nil, r)
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("replay: %v, want %v", got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+//-smooth picks how fly mode and the spinning sphere ease toward their goal
+//speeds, e.g.
+//	go run . -smooth damp
+
+import (
+	"flag"
+
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var smoothing = flag.String("smooth", "approach", "how fly mode and the sphere ease to their speeds, one of approach, damp, decay")
+
+//set up the smoothing asked for on the command line
+func setupSmoothing(d *sim.Demo) error {
+
+	s, err := sim.ParseSmoothing(*smoothing)
+	if err != nil {
+		return err
+	}
+	d.SetSmoothing(s)
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+//-ship-mass, -ship-thrust, -ship-inertia and -ship-torque tune the spaceship
+//mode, the fifth M mode, -flight-assist starts it with the assist on, e.g.
+//	go run . -ship-mass 50 -ship-inertia 40,40,10 -flight-assist
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var (
+	shipMass     = flag.Float64("ship-mass", 10, "spaceship mode mass the thrusters push")
+	shipThrust   = flag.Float64("ship-thrust", 40, "spaceship mode force of the thrusters, the acceleration is this over the mass")
+	shipInertia  = flag.String("ship-inertia", "10,10,5", "spaceship mode moment of inertia about the pitch, yaw and roll axes")
+	shipTorque   = flag.Float64("ship-torque", 15, "spaceship mode torque of the rotation thrusters")
+	flightAssist = flag.Bool("flight-assist", false, "start spaceship mode with the flight assist braking, A toggles it")
+)
+
+//set up spaceship mode's thrusters as asked on the command line
+func setupThrusters(d *sim.Demo) error {
+
+	inertia, err := parseVector(*shipInertia)
+	if err != nil {
+		return fmt.Errorf("-ship-inertia: %v", err)
+	}
+	if *shipMass <= 0 || inertia.X <= 0 || inertia.Y <= 0 || inertia.Z <= 0 {
+		return fmt.Errorf("-ship-mass and -ship-inertia must be above 0")
+	}
+	if *shipThrust < 0 || *shipTorque < 0 {
+		return fmt.Errorf("-ship-thrust and -ship-torque must not be below 0")
+	}
+	d.Mover.SetThrusters(sim.Thrusters{Mass: float32(*shipMass), Force: float32(*shipThrust),
+		Inertia: inertia, Torque: float32(*shipTorque)})
+	d.Mover.SetFlightAssist(*flightAssist)
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+//-switch-time and -switch-ease tune how N blends the camera over to the node
+//it hands control to, 0 switches at once as it used to, e.g.
+//	go run . -switch-time 2 -switch-ease cubic
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Juuliuus/g3nmovedemo/motion"
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var (
+	switchTime = flag.Float64("switch-time", 1, "seconds N takes to blend the camera over before handing control over, 0 switches at once")
+	switchEase = flag.String("switch-ease", "ease-in-out", "easing of the N blend, one of "+strings.Join(motion.EasingNames(), ", "))
+)
+
+//set up the N switch as asked on the command line
+func setupSwitch(d *sim.Demo) error {
+
+	ease, err := motion.ParseEasing(*switchEase)
+	if err != nil {
+		return fmt.Errorf("-switch-ease: %v", err)
+	}
+	if *switchTime < 0 {
+		return fmt.Errorf("-switch-time must not be below 0")
+	}
+	d.Switch = sim.NodeSwitch{Duration: float32(*switchTime), Ease: ease}
+	return nil
+}
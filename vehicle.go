@@ -0,0 +1,39 @@
+package main
+
+//-wheelbase, -max-steer, -engine, -grip and -handbrake-grip tune the
+//vehicle mode, the seventh M mode, e.g.
+//	go run . -engine 10 -grip 15 -handbrake-grip 1
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/Juuliuus/g3nmovedemo/sim"
+)
+
+var (
+	wheelbase     = flag.Float64("wheelbase", 1.2, "vehicle mode distance between the front and the rear wheel")
+	maxSteer      = flag.Float64("max-steer", 0.6, "vehicle mode steering lock, radians")
+	engine        = flag.Float64("engine", 6, "vehicle mode acceleration at full throttle, units per second squared")
+	grip          = flag.Float64("grip", 25, "vehicle mode sideways slide the tyres stop, units per second squared")
+	handbrakeGrip = flag.Float64("handbrake-grip", 3, "vehicle mode grip left with the handbrake on")
+)
+
+//set up vehicle mode's handling as asked on the command line
+func setupHandling(d *sim.Demo) error {
+
+	if *wheelbase <= 0 || *maxSteer <= 0 || *maxSteer >= 1.5 {
+		return fmt.Errorf("-wheelbase must be above 0, -max-steer between 0 and 1.5")
+	}
+	if *engine < 0 || *grip < 0 || *handbrakeGrip < 0 {
+		return fmt.Errorf("-engine, -grip and -handbrake-grip must not be below 0")
+	}
+	h := d.Mover.Handling()
+	h.Wheelbase = float32(*wheelbase)
+	h.MaxSteer = float32(*maxSteer)
+	h.Engine = float32(*engine)
+	h.Grip = float32(*grip)
+	h.HandbrakeGrip = float32(*handbrakeGrip)
+	d.Mover.SetHandling(h)
+	return nil
+}
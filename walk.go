@@ -0,0 +1,67 @@
+package main
+
+//walk mode, the fourth M mode, looks around with the mouse: while walking the
+//cursor is captured and the orbit control is off. -mouse-sensitivity,
+//-eye-height and -head-bob tune it, e.g.
+//	go run . -head-bob 0
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/Juuliuus/g3nmovedemo/sim"
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/window"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+var (
+	mouseSensitivity = flag.Float64("mouse-sensitivity", 0.003, "walk mode radians turned per pixel the mouse moves")
+	eyeHeight        = flag.Float64("eye-height", 1.7, "walk mode height of the eye above the floor")
+	headBob          = flag.Float64("head-bob", 0.05, "walk mode head bob up and down, 0 is none")
+)
+
+//set up walk mode as asked on the command line
+func setupWalking(d *sim.Demo) error {
+
+	if *mouseSensitivity <= 0 || *headBob < 0 {
+		return fmt.Errorf("-mouse-sensitivity must be above 0 and -head-bob not below")
+	}
+	w := d.Mover.Walking()
+	w.Sensitivity = float32(*mouseSensitivity)
+	w.EyeHeight = float32(*eyeHeight)
+	w.Bob = float32(*headBob)
+	d.Mover.SetWalking(w)
+	return nil
+}
+
+//the mouse moves go to the simulation while walking, they are applied and
+//recorded at the next tick like the keys
+func (gm *GameApp) onCursor(evname string, ev interface{}) {
+
+	cev := ev.(*window.CursorEvent)
+	if gm.mouseLook && gm.cursorSeen && demo != nil {
+		demo.loop.OnMouseLook(cev.Xpos-gm.cursorX, cev.Ypos-gm.cursorY)
+	}
+	gm.cursorX, gm.cursorY, gm.cursorSeen = cev.Xpos, cev.Ypos, true
+}
+
+//capture the mouse for walk mode, hand it back to the orbit control after
+func (gm *GameApp) setMouseLook(on bool) {
+
+	if on == gm.mouseLook {
+		return
+	}
+	gm.mouseLook = on
+	//the cursor jumps when it is captured or let go, that is no mouse look
+	gm.cursorSeen = false
+
+	w := gm.IWindow.(*window.GlfwWindow)
+	if on {
+		gm.orbit.SetEnabled(camera.OrbitNone)
+		w.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+		return
+	}
+	gm.orbit.SetEnabled(camera.OrbitAll)
+	w.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
+}